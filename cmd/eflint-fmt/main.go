@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+)
+
+// eflint-fmt prints a JSON specification of eFLINT phrases, as produced by
+// eflint-to-json, as an eFLINT program.
+func main() {
+	file := os.Stdin
+	if len(os.Args) > 1 {
+		f, err := os.Open(os.Args[1])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		file = f
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var input eflint.Input
+	if err := json.Unmarshal(data, &input); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	result, err := eflint.FormatInput(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Print(result)
+}
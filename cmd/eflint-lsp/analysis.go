@@ -0,0 +1,467 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/parser"
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// declaration is a fact, predicate, event, act, duty or placeholder that is
+// declared in a document or in one of the files it includes.
+type declaration struct {
+	name     string
+	kind     string
+	filename string
+	span     parser.Span
+	// offset is the position of the name in the declaration
+	offset int
+	phrase parser.Phrase
+}
+
+// analysis is the result of parsing and checking a document.
+type analysis struct {
+	filename     string
+	input        *parser.Input
+	declarations []*declaration
+	byName       map[string]*declaration
+	// texts contains the source of the document and of all included files,
+	// by filename
+	texts map[string]string
+}
+
+// analyse parses and checks the text of a document. The analysis is nil when
+// the document cannot be parsed.
+func analyse(filename string, text string) (*analysis, []diagnostic) {
+	input, err := parser.Parse(filename, strings.NewReader(text))
+	if err != nil {
+		return nil, []diagnostic{parseDiagnostic(filename, text, err)}
+	}
+
+	a := &analysis{
+		filename:     filename,
+		input:        input,
+		declarations: make([]*declaration, 0),
+		byName:       make(map[string]*declaration),
+		texts:        map[string]string{filename: text},
+	}
+
+	for _, phrase := range input.Phrases {
+		name, kind := declares(phrase)
+		if name == "" {
+			continue
+		}
+
+		span := phrase.Location()
+		d := &declaration{
+			name:     name,
+			kind:     kind,
+			filename: span.Pos.Filename,
+			span:     span,
+			offset:   a.nameOffset(span, name),
+			phrase:   phrase,
+		}
+
+		a.declarations = append(a.declarations, d)
+		a.byName[name] = d
+	}
+
+	return a, a.diagnostics()
+}
+
+// declares returns the name and the kind of the declaration made by a phrase.
+func declares(phrase parser.Phrase) (string, string) {
+	switch p := phrase.(type) {
+	case parser.Fact:
+		return p.Name, "fact"
+	case parser.Predicate:
+		if p.IsInvariant {
+			return p.Name, "invariant"
+		}
+		return p.Name, "predicate"
+	case parser.Event:
+		return p.Name, "event"
+	case parser.Act:
+		return p.Name, "act"
+	case parser.Duty:
+		return p.Name, "duty"
+	case parser.Placeholder:
+		if len(p.Name) > 0 {
+			return p.Name[0], "placeholder"
+		}
+	}
+
+	return "", ""
+}
+
+// diagnostics validates the document, and typechecks its phrases against the
+// declarations before them. Only problems in the document itself are
+// reported, not those in included files. The typechecker of the interpreter
+// does not check the types of values yet, so most problems are found by
+// validation.
+func (a *analysis) diagnostics() []diagnostic {
+	diagnostics := make([]diagnostic, 0)
+	failed := make(map[int]bool)
+
+	for _, d := range parser.Validate(a.input) {
+		if d.Pos.Filename != a.filename {
+			continue
+		}
+
+		severity := severityWarning
+		if d.Severity == parser.SeverityError {
+			severity = severityError
+			failed[d.Pos.Offset] = true
+		}
+
+		diagnostics = append(diagnostics, diagnostic{
+			Range:    a.phraseRange(d.Pos),
+			Severity: severity,
+			Source:   "eflint",
+			Message:  d.Message,
+		})
+	}
+
+	phrases, err := parser.ToPhrases(a.input)
+	if err != nil {
+		return append(diagnostics, diagnostic{
+			Severity: severityError,
+			Source:   "eflint",
+			Message:  err.Error(),
+		})
+	}
+
+	// The typechecker looks up declared facts in the state of the
+	// interpreter, so all declarations are interpreted along the way
+	eflint.Reset()
+
+	for i, phrase := range phrases {
+		pos := a.input.Phrases[i].Location().Pos
+		if pos.Filename == a.filename && !failed[pos.Offset] {
+			if err := typecheck(&phrase); err != nil {
+				diagnostics = append(diagnostics, diagnostic{
+					Range:    a.phraseRange(pos),
+					Severity: severityError,
+					Source:   "eflint",
+					Message:  err.Error(),
+				})
+			}
+		}
+
+		if isDeclaration(phrase.Kind) {
+			declare(phrase)
+		}
+	}
+
+	return diagnostics
+}
+
+func typecheck(phrase *eflint.Phrase) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	return eflint.TypecheckPhrase(*phrase)
+}
+
+func declare(phrase eflint.Phrase) {
+	defer func() {
+		recover()
+	}()
+
+	eflint.InterpretPhrase(phrase)
+}
+
+func isDeclaration(kind string) bool {
+	switch kind {
+	case "afact", "cfact", "placeholder", "predicate", "event", "act", "duty", "extend":
+		return true
+	}
+
+	return false
+}
+
+// parseDiagnostic turns a parse error into a diagnostic. Errors in included
+// files are reported at the start of the document.
+func parseDiagnostic(filename string, text string, err error) diagnostic {
+	r := textRange{}
+
+	var perr participle.Error
+	if errors.As(err, &perr) && perr.Position().Filename == filename {
+		start := toPosition(text, perr.Position().Offset)
+		r = textRange{Start: start, End: toPosition(text, wordEnd(text, perr.Position().Offset))}
+		if perr.Error() == err.Error() {
+			err = errors.New(perr.Message())
+		}
+	}
+
+	return diagnostic{
+		Range:    r,
+		Severity: severityError,
+		Source:   "eflint",
+		Message:  err.Error(),
+	}
+}
+
+// phraseRange returns the range of the phrase that starts at pos.
+func (a *analysis) phraseRange(pos lexer.Position) textRange {
+	text := a.texts[a.filename]
+
+	for _, phrase := range a.input.Phrases {
+		if span := phrase.Location(); span.Pos == pos {
+			return textRange{
+				Start: toPosition(text, span.Pos.Offset),
+				End:   toPosition(text, span.EndPos.Offset),
+			}
+		}
+	}
+
+	start := toPosition(text, pos.Offset)
+	return textRange{Start: start, End: toPosition(text, wordEnd(text, pos.Offset))}
+}
+
+// text returns the source of a file, reading included files from disk.
+func (a *analysis) text(filename string) string {
+	if text, ok := a.texts[filename]; ok {
+		return text
+	}
+
+	data, _ := os.ReadFile(filename)
+	a.texts[filename] = string(data)
+
+	return a.texts[filename]
+}
+
+// nameOffset returns the offset of the first occurrence of name in the span,
+// which is where a declaration names what it declares.
+func (a *analysis) nameOffset(span parser.Span, name string) int {
+	text := a.text(span.Pos.Filename)
+	if span.EndPos.Offset > len(text) || span.Pos.Offset > span.EndPos.Offset {
+		return span.Pos.Offset
+	}
+
+	pattern := regexp.MustCompile(`(^|[^a-zA-Z0-9_'-])` + regexp.QuoteMeta(name) + `($|[^a-zA-Z0-9_'-])`)
+	if match := pattern.FindStringSubmatchIndex(text[span.Pos.Offset:span.EndPos.Offset]); match != nil {
+		return span.Pos.Offset + match[3]
+	}
+
+	return span.Pos.Offset
+}
+
+// nameRange returns the range of the name of a declaration.
+func (a *analysis) nameRange(d *declaration) textRange {
+	text := a.text(d.filename)
+
+	return textRange{
+		Start: toPosition(text, d.offset),
+		End:   toPosition(text, d.offset+len(d.name)),
+	}
+}
+
+// spanRange returns the range of a whole declaration.
+func (a *analysis) spanRange(d *declaration) textRange {
+	text := a.text(d.filename)
+
+	return textRange{
+		Start: toPosition(text, d.span.Pos.Offset),
+		End:   toPosition(text, d.span.EndPos.Offset),
+	}
+}
+
+// lookup returns the declaration a name refers to. Decorated names, such as
+// person1 and person', refer to the declaration of person.
+func (a *analysis) lookup(name string) *declaration {
+	if d, ok := a.byName[name]; ok {
+		return d
+	}
+
+	return a.byName[strings.TrimRight(name, "'0123456789")]
+}
+
+// resolve follows placeholders to the declaration they stand for.
+func (a *analysis) resolve(d *declaration) *declaration {
+	seen := make(map[*declaration]bool)
+
+	for d != nil && d.kind == "placeholder" && !seen[d] {
+		seen[d] = true
+		d = a.lookup(d.phrase.(parser.Placeholder).For)
+	}
+
+	return d
+}
+
+// names returns the names of all declarations in alphabetical order.
+func (a *analysis) names() []string {
+	names := make([]string, 0, len(a.byName))
+	for name := range a.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// describe returns the heading of a declaration, which shows how its
+// instances are identified.
+func (a *analysis) describe(d *declaration) string {
+	var b strings.Builder
+
+	switch p := d.phrase.(type) {
+	case parser.Fact:
+		b.WriteString("Fact " + p.Name + " Identified by ")
+		switch {
+		case len(p.IdentifiedBy) > 0:
+			b.WriteString(strings.Join(p.IdentifiedBy, " * "))
+		case len(p.Range) > 0:
+			b.WriteString(formatRange(p.Range))
+		default:
+			b.WriteString(p.Type)
+		}
+	case parser.Predicate:
+		if p.IsInvariant {
+			b.WriteString("Invariant " + p.Name)
+		} else {
+			b.WriteString("Predicate " + p.Name)
+		}
+	case parser.Event:
+		b.WriteString("Event " + p.Name)
+		writeRelatedTo(&b, p.RelatedTo)
+	case parser.Act:
+		b.WriteString("Act " + p.Name)
+		if p.Actor != "" {
+			b.WriteString("\n  Actor " + p.Actor)
+		}
+		if p.Recipient != "" {
+			b.WriteString("\n  Recipient " + p.Recipient)
+		}
+		writeRelatedTo(&b, p.RelatedTo)
+	case parser.Duty:
+		b.WriteString("Duty " + p.Name)
+		b.WriteString("\n  Holder " + p.Holder)
+		b.WriteString("\n  Claimant " + p.Claimant)
+		writeRelatedTo(&b, p.RelatedTo)
+	case parser.Placeholder:
+		b.WriteString("Placeholder " + d.name + " For " + p.For)
+		if target := a.resolve(d); target != nil {
+			b.WriteString("\n" + a.describe(target))
+		}
+	}
+
+	return b.String()
+}
+
+func writeRelatedTo(b *strings.Builder, names []string) {
+	if len(names) > 0 {
+		b.WriteString("\n  Related to " + strings.Join(names, ", "))
+	}
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Z][a-zA-Z0-9_]*$`)
+
+// formatRange formats the values of an enumerated fact, collapsing a range
+// of consecutive integers.
+func formatRange(values []parser.Range) string {
+	first, firstOk := values[0].(parser.Int)
+	last, lastOk := values[len(values)-1].(parser.Int)
+	if len(values) > 2 && firstOk && lastOk && last.Value-first.Value == int64(len(values)-1) {
+		return fmt.Sprintf("%d..%d", first.Value, last.Value)
+	}
+
+	formatted := make([]string, 0, len(values))
+	for _, value := range values {
+		switch v := value.(type) {
+		case parser.Int:
+			formatted = append(formatted, strconv.FormatInt(v.Value, 10))
+		case parser.String:
+			if identifierPattern.MatchString(v.Value) {
+				formatted = append(formatted, v.Value)
+			} else {
+				formatted = append(formatted, strconv.Quote(v.Value))
+			}
+		}
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+func isNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '\'' || c == '-'
+}
+
+// wordAt returns the word around offset, and the offset at which it starts.
+func wordAt(text string, offset int) (string, int) {
+	if offset > len(text) {
+		offset = len(text)
+	}
+
+	start := offset
+	for start > 0 && isNameByte(text[start-1]) {
+		start--
+	}
+
+	return text[start:wordEnd(text, offset)], start
+}
+
+func wordEnd(text string, offset int) int {
+	end := offset
+	for end < len(text) && isNameByte(text[end]) {
+		end++
+	}
+
+	return end
+}
+
+// toPosition converts a byte offset to a position, of which the character is
+// counted in UTF-16 code units as the protocol requires.
+func toPosition(text string, offset int) position {
+	if offset > len(text) {
+		offset = len(text)
+	}
+
+	p := position{}
+	for i, r := range text[:offset] {
+		if r == '\n' {
+			p.Line++
+			p.Character = 0
+		} else if i+utf8.RuneLen(r) <= offset {
+			p.Character += len(utf16.Encode([]rune{r}))
+		}
+	}
+
+	return p
+}
+
+// toOffset converts a position to a byte offset.
+func toOffset(text string, p position) int {
+	line, character := 0, 0
+
+	for i, r := range text {
+		if line == p.Line && character >= p.Character {
+			return i
+		}
+
+		if r == '\n' {
+			if line == p.Line {
+				return i
+			}
+			line++
+			character = 0
+		} else if line == p.Line {
+			character += len(utf16.Encode([]rune{r}))
+		}
+	}
+
+	return len(text)
+}
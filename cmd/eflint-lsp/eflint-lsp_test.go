@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// message is a response or notification written by the server.
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Params json.RawMessage `json:"params"`
+	Error  *responseError  `json:"error"`
+}
+
+// session runs the server on the given requests until they run out, and
+// returns everything it wrote together with its exit code.
+func session(t *testing.T, requests ...interface{}) ([]message, int) {
+	t.Helper()
+
+	var in bytes.Buffer
+	for _, req := range requests {
+		if err := writeMessage(&in, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	code := newServer(&out).serve(bufio.NewReader(&in))
+
+	messages := make([]message, 0)
+	r := bufio.NewReader(&out)
+	for {
+		body, err := readMessage(r)
+		if err != nil {
+			break
+		}
+
+		var m message
+		if err := json.Unmarshal(body, &m); err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, code
+}
+
+func call(id int, method string, params interface{}) map[string]interface{} {
+	req := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+	if id != 0 {
+		req["id"] = id
+	}
+	return req
+}
+
+// open returns the didOpen notification of a fixture in the tests directory.
+func open(t *testing.T, name string) (map[string]interface{}, string) {
+	t.Helper()
+
+	path, err := filepath.Abs(filepath.Join("tests", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uri := "file://" + filepath.ToSlash(path)
+	return call(0, "textDocument/didOpen", didOpenParams{
+		TextDocument: textDocumentItem{URI: uri, Version: 1, Text: string(text)},
+	}), uri
+}
+
+func at(uri string, line int, character int) textDocumentPositionParams {
+	var params textDocumentPositionParams
+	params.TextDocument.URI = uri
+	params.Position = position{Line: line, Character: character}
+	return params
+}
+
+func TestInitialize(t *testing.T) {
+	messages, code := session(t,
+		call(1, "initialize", map[string]interface{}{}),
+		call(0, "initialized", map[string]interface{}{}),
+		call(2, "shutdown", nil),
+		call(0, "exit", nil),
+	)
+
+	if code != 0 {
+		t.Errorf("Expected exit code 0 after a shutdown, got %d", code)
+	}
+
+	if len(messages) != 2 || string(messages[0].ID) != "1" || string(messages[1].ID) != "2" {
+		t.Fatalf("Expected responses to initialize and shutdown, got %+v", messages)
+	}
+
+	var result initializeResult
+	if err := json.Unmarshal(messages[0].Result, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	capabilities := result.Capabilities
+	if capabilities.TextDocumentSync != 1 || !capabilities.DefinitionProvider || !capabilities.HoverProvider || !capabilities.DocumentSymbolProvider {
+		t.Errorf("Expected full sync, definitions, hovers and symbols, got %+v", capabilities)
+	}
+
+	if result.ServerInfo.Name != "eflint-lsp" {
+		t.Errorf("Expected the server name, got %+v", result.ServerInfo)
+	}
+
+	if _, code := session(t, call(0, "exit", nil)); code != 1 {
+		t.Errorf("Expected exit code 1 without a shutdown, got %d", code)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		fixture string
+		// expected contains, for every diagnostic, its line and a part of
+		// its message
+		expected []struct {
+			line    int
+			message string
+		}
+	}{
+		{"citizens.eflint", nil},
+		{"problems.eflint", []struct {
+			line    int
+			message string
+		}{
+			{2, "resident"},
+			{5, "vote"},
+		}},
+		{"unparsable.eflint", []struct {
+			line    int
+			message string
+		}{
+			{2, "expected )"},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.fixture, func(t *testing.T) {
+			didOpen, uri := open(t, test.fixture)
+			messages, _ := session(t, didOpen)
+
+			if len(messages) != 1 || messages[0].Method != "textDocument/publishDiagnostics" {
+				t.Fatalf("Expected the diagnostics to be published, got %+v", messages)
+			}
+
+			var params publishDiagnosticsParams
+			if err := json.Unmarshal(messages[0].Params, &params); err != nil {
+				t.Fatal(err)
+			}
+
+			if params.URI != uri {
+				t.Errorf("Expected the diagnostics of %s, got those of %s", uri, params.URI)
+			}
+
+			if len(params.Diagnostics) != len(test.expected) {
+				t.Fatalf("Expected %d diagnostics, got %+v", len(test.expected), params.Diagnostics)
+			}
+
+			for i, expected := range test.expected {
+				d := params.Diagnostics[i]
+				if d.Severity != severityError || d.Range.Start.Line != expected.line || !strings.Contains(d.Message, expected.message) {
+					t.Errorf("Expected an error on line %d about %s, got %+v", expected.line, expected.message, d)
+				}
+			}
+		})
+	}
+}
+
+func TestNavigation(t *testing.T) {
+	didOpen, uri := open(t, "citizens.eflint")
+
+	messages, _ := session(t,
+		didOpen,
+		// adult in +adult(Alice)
+		call(1, "textDocument/definition", at(uri, 3, 2)),
+		call(2, "textDocument/hover", at(uri, 3, 2)),
+		// person in the declaration of adult, which is a placeholder
+		call(3, "textDocument/definition", at(uri, 2, 27)),
+		// Alice is not declared
+		call(4, "textDocument/definition", at(uri, 3, 8)),
+	)
+
+	if len(messages) != 5 {
+		t.Fatalf("Expected diagnostics and four responses, got %+v", messages)
+	}
+
+	var adult location
+	if err := json.Unmarshal(messages[1].Result, &adult); err != nil {
+		t.Fatal(err)
+	}
+	if adult.URI != uri || adult.Range.Start != (position{Line: 2, Character: 5}) || adult.Range.End != (position{Line: 2, Character: 10}) {
+		t.Errorf("Expected the definition of adult on line 2, got %+v", adult)
+	}
+
+	var h hover
+	if err := json.Unmarshal(messages[2].Result, &h); err != nil {
+		t.Fatal(err)
+	}
+	if h.Contents.Kind != "markdown" || !strings.Contains(h.Contents.Value, "Fact adult Identified by person") {
+		t.Errorf("Expected the declaration of adult on hover, got %+v", h)
+	}
+	if h.Range.Start != (position{Line: 3, Character: 1}) || h.Range.End != (position{Line: 3, Character: 6}) {
+		t.Errorf("Expected the hover to cover adult, got %+v", h.Range)
+	}
+
+	var person location
+	if err := json.Unmarshal(messages[3].Result, &person); err != nil {
+		t.Fatal(err)
+	}
+	if person.Range.Start.Line != 1 {
+		t.Errorf("Expected the definition of the placeholder person on line 1, got %+v", person)
+	}
+
+	if string(messages[4].Result) != "null" {
+		t.Errorf("Expected no definition of Alice, got %s", messages[4].Result)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	didOpen, uri := open(t, "citizens.eflint")
+
+	messages, _ := session(t, didOpen, call(1, "textDocument/completion", at(uri, 4, 0)))
+	if len(messages) != 2 {
+		t.Fatalf("Expected diagnostics and a response, got %+v", messages)
+	}
+
+	var items []completionItem
+	if err := json.Unmarshal(messages[1].Result, &items); err != nil {
+		t.Fatal(err)
+	}
+
+	offered := make(map[string]bool)
+	for _, item := range items {
+		offered[item.Label] = true
+	}
+
+	// Violated is only a keyword as part of a Violated when clause
+	for label, expected := range map[string]bool{"adult": true, "person": true, "Violated when": true, "Violated": false} {
+		if offered[label] != expected {
+			t.Errorf("Expected %s to be offered: %t, got %t", label, expected, offered[label])
+		}
+	}
+}
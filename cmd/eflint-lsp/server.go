@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+)
+
+// keywords are offered as completions next to the declared names.
+var keywords = []string{
+	"Fact", "Identified by", "Placeholder", "For", "Predicate", "Invariant",
+	"Event", "Act", "Duty", "Extend", "Actor", "Recipient", "Holder",
+	"Claimant", "Related to", "Derived from", "Holds when", "Conditioned by",
+	"Violated when", "Syncs with", "Creates", "Terminates", "Obfuscates",
+	"Foreach", "Exists", "Forall", "When", "Holds", "Enabled", "Count",
+	"Count distinct", "Sum", "Max", "Min", "Avg", "Not", "True", "False",
+	"String", "Int", "Bool", "#include", "#require",
+}
+
+// document is a file that is open in the editor.
+type document struct {
+	uri      string
+	filename string
+	text     string
+	// analysis is the last analysis of the document that could be parsed,
+	// so that completion keeps working while a phrase is being typed
+	analysis *analysis
+}
+
+type server struct {
+	out       io.Writer
+	documents map[string]*document
+	shutdown  bool
+}
+
+func newServer(out io.Writer) *server {
+	return &server{
+		out:       out,
+		documents: make(map[string]*document),
+	}
+}
+
+// serve handles messages until the client exits, and returns the exit code.
+func (s *server) serve(r *bufio.Reader) int {
+	for {
+		body, err := readMessage(r)
+		if errors.Is(err, io.EOF) {
+			return 1
+		} else if err != nil {
+			log.Println(err)
+			return 1
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()})
+			continue
+		}
+
+		if req.Method == "exit" {
+			if s.shutdown {
+				return 0
+			}
+			return 1
+		}
+
+		result, err := s.handle(req)
+
+		// Notifications are never answered
+		if req.ID == nil {
+			if err != nil {
+				log.Println(req.Method+":", err)
+			}
+			continue
+		}
+
+		var rerr *responseError
+		if err != nil && !errors.As(err, &rerr) {
+			rerr = &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+
+		s.reply(req.ID, result, rerr)
+	}
+}
+
+func (s *server) reply(id json.RawMessage, result interface{}, err *responseError) {
+	var message interface{} = response{JSONRPC: "2.0", ID: id, Result: result}
+	if err != nil {
+		message = errorResponse{JSONRPC: "2.0", ID: id, Error: *err}
+	}
+
+	if werr := writeMessage(s.out, message); werr != nil {
+		log.Println(werr)
+	}
+}
+
+func (s *server) notify(method string, params interface{}) {
+	if err := writeMessage(s.out, notification{JSONRPC: "2.0", Method: method, Params: params}); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *server) handle(req request) (interface{}, error) {
+	if s.shutdown && req.Method != "exit" {
+		return nil, &responseError{Code: codeInvalidRequest, Message: "server is shut down"}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return s.initialize(), nil
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		s.update(params.TextDocument.URI, params.TextDocument.Text)
+		return nil, nil
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			s.update(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		delete(s.documents, params.TextDocument.URI)
+		s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []diagnostic{}})
+		return nil, nil
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(params), nil
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params), nil
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.completion(params), nil
+	case "textDocument/documentSymbol":
+		var params documentSymbolParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.documentSymbols(params), nil
+	}
+
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+}
+
+func (s *server) initialize() initializeResult {
+	var result initializeResult
+
+	result.Capabilities.TextDocumentSync = 1
+	result.Capabilities.DefinitionProvider = true
+	result.Capabilities.HoverProvider = true
+	result.Capabilities.DocumentSymbolProvider = true
+	result.Capabilities.CompletionProvider.TriggerCharacters = []string{"(", ","}
+	result.ServerInfo.Name = "eflint-lsp"
+	result.ServerInfo.Version = eflint.ReasonerVersion
+
+	return result
+}
+
+// update analyses the new text of a document and publishes its diagnostics.
+func (s *server) update(uri string, text string) {
+	d, ok := s.documents[uri]
+	if !ok {
+		d = &document{uri: uri, filename: uriToFilename(uri)}
+		s.documents[uri] = d
+	}
+
+	d.text = text
+
+	a, diagnostics := analyse(d.filename, text)
+	if a != nil {
+		d.analysis = a
+	}
+
+	s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+// declarationAt returns the declaration that the name at a position in a
+// document refers to, together with the range of that name.
+func (s *server) declarationAt(params textDocumentPositionParams) (*document, *declaration, textRange) {
+	d, ok := s.documents[params.TextDocument.URI]
+	if !ok || d.analysis == nil {
+		return nil, nil, textRange{}
+	}
+
+	word, start := wordAt(d.text, toOffset(d.text, params.Position))
+	if word == "" {
+		return nil, nil, textRange{}
+	}
+
+	r := textRange{Start: toPosition(d.text, start), End: toPosition(d.text, start+len(word))}
+
+	return d, d.analysis.lookup(word), r
+}
+
+func (s *server) definition(params textDocumentPositionParams) interface{} {
+	d, decl, _ := s.declarationAt(params)
+	if decl == nil {
+		return nil
+	}
+
+	return location{
+		URI:   filenameToURI(decl.filename, d),
+		Range: d.analysis.nameRange(decl),
+	}
+}
+
+func (s *server) hover(params textDocumentPositionParams) interface{} {
+	d, decl, r := s.declarationAt(params)
+	if decl == nil {
+		return nil
+	}
+
+	return hover{
+		Contents: markupContent{
+			Kind:  "markdown",
+			Value: "```eflint\n" + d.analysis.describe(decl) + "\n```",
+		},
+		Range: r,
+	}
+}
+
+func (s *server) completion(params textDocumentPositionParams) []completionItem {
+	items := make([]completionItem, 0)
+
+	if d, ok := s.documents[params.TextDocument.URI]; ok && d.analysis != nil {
+		for _, name := range d.analysis.names() {
+			decl := d.analysis.byName[name]
+			items = append(items, completionItem{
+				Label:  name,
+				Kind:   completionKinds[decl.kind],
+				Detail: strings.SplitN(d.analysis.describe(decl), "\n", 2)[0],
+			})
+		}
+	}
+
+	for _, keyword := range keywords {
+		items = append(items, completionItem{Label: keyword, Kind: completionKeyword})
+	}
+
+	return items
+}
+
+var completionKinds = map[string]int{
+	"fact":        completionClass,
+	"predicate":   completionClass,
+	"invariant":   completionClass,
+	"event":       completionEvent,
+	"act":         completionFunction,
+	"duty":        completionInterface,
+	"placeholder": completionVariable,
+}
+
+var symbolKinds = map[string]int{
+	"fact":        symbolClass,
+	"predicate":   symbolClass,
+	"invariant":   symbolClass,
+	"event":       symbolEvent,
+	"act":         symbolFunction,
+	"duty":        symbolInterface,
+	"placeholder": symbolVariable,
+}
+
+func (s *server) documentSymbols(params documentSymbolParams) []documentSymbol {
+	symbols := make([]documentSymbol, 0)
+
+	d, ok := s.documents[params.TextDocument.URI]
+	if !ok || d.analysis == nil {
+		return symbols
+	}
+
+	for _, decl := range d.analysis.declarations {
+		// Declarations in included files belong to those files
+		if decl.filename != d.filename {
+			continue
+		}
+
+		symbols = append(symbols, documentSymbol{
+			Name:           decl.name,
+			Detail:         decl.kind,
+			Kind:           symbolKinds[decl.kind],
+			Range:          d.analysis.spanRange(decl),
+			SelectionRange: d.analysis.nameRange(decl),
+		})
+	}
+
+	return symbols
+}
+
+// uriToFilename returns the path of a file URI, which is used to resolve the
+// files that a document includes.
+func uriToFilename(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+
+	return filepath.FromSlash(u.Path)
+}
+
+// filenameToURI returns the URI of a file, reusing the URI of the document
+// for the document itself.
+func filenameToURI(filename string, d *document) string {
+	if filename == d.filename {
+		return d.uri
+	}
+
+	if path, err := filepath.Abs(filename); err == nil {
+		filename = path
+	}
+
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(filename)}).String()
+}
@@ -3,16 +3,108 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflintpb"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/logging"
 	"github.com/Olaf-Erkemeij/eflint-server/internal/parser"
+	"github.com/Olaf-Erkemeij/eflint-server/pkg/client"
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
+// compile parses an eFLINT program, and returns it with the request for its
+// phrases.
+func compile(t *testing.T, filename string, src string) (*parser.Input, []byte) {
+	t.Helper()
+
+	input, err := parser.Parse(filename, strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return input, data
+}
+
+// readRequest parses a file in the tests directory into a request, to which
+// the fields of other kinds of requests can be added.
+func readRequest(t *testing.T, path string) map[string]interface{} {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	data, err := parser.ParseFile(path, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var request map[string]interface{}
+	if err := json.Unmarshal(data, &request); err != nil {
+		t.Fatal(err)
+	}
+
+	return request
+}
+
+// post sends a request to the HTTP handler, and decodes the response into
+// output unless it is nil. The body is sent as it is if it is a string or a
+// byte slice, and as JSON otherwise.
+func post(t *testing.T, target string, body interface{}, output interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var data []byte
+	switch body := body.(type) {
+	case string:
+		data = []byte(body)
+	case []byte:
+		data = body
+	default:
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	request, _ := http.NewRequest("POST", target, bytes.NewReader(data))
+	response := httptest.NewRecorder()
+
+	eFLINTHandler(response, request)
+
+	if output != nil {
+		if err := json.Unmarshal(response.Body.Bytes(), output); err != nil {
+			t.Fatalf("cannot decode the response: %v\n%s", err, response.Body.String())
+		}
+	}
+
+	return response
+}
+
 func TestServer(t *testing.T) {
 	// Go over all the files in the test directory
 	// and run the tests
@@ -35,8 +127,12 @@ func TestServer(t *testing.T) {
 			defer file.Close()
 
 			// Parse the file
-			data, err := parser.ParseFile(path, file)
+			input, err := parser.Parse(path, file)
+			if err != nil {
+				t.Fatal(err)
+			}
 
+			data, err := json.Marshal(input)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -61,16 +157,16 @@ func TestServer(t *testing.T) {
 
 			results := result["results"].([]interface{})
 
-			file.Seek(0, 0)
-
-			scanner := bufio.NewScanner(file)
+			if len(results) != len(input.Phrases) {
+				t.Fatalf("Expected %d results, got %d", len(input.Phrases), len(results))
+			}
 
-			for index := 0; scanner.Scan(); index += 1 {
+			for index, phrase := range input.Phrases {
 				res := results[index].(map[string]interface{})
 
 				if queryResult, ok := res["result"]; ok {
 					if queryBool, ok := queryResult.(bool); !ok || !queryBool {
-						t.Fatal("Query returned false:", scanner.Text())
+						t.Fatalf("%s: query returned false", phrase.Location().Pos)
 					}
 				}
 			}
@@ -80,6 +176,1227 @@ func TestServer(t *testing.T) {
 	})
 }
 
+func TestDuties(t *testing.T) {
+	// Request a duty report instead of the phrase results
+	input := readRequest(t, "tests/reports/duties.eflint")
+	input["kind"] = "duties"
+
+	var result struct {
+		Success bool `json:"success"`
+		Duties  []struct {
+			Holder   interface{} `json:"holder"`
+			Claimant interface{} `json:"claimant"`
+			Duties   []struct {
+				Duty struct {
+					Identifier string `json:"identifier"`
+				} `json:"duty"`
+				Violated bool `json:"violated"`
+			} `json:"duties"`
+		} `json:"duties"`
+	}
+	post(t, "/", input, &result)
+
+	if !result.Success {
+		t.Fatal("Expected success to be true")
+	}
+
+	expected := []struct {
+		identifier string
+		violated   []bool
+	}{
+		{"deliver", []bool{false}},
+		{"pay", []bool{true}},
+		{"pay", []bool{false}},
+	}
+
+	if len(result.Duties) != len(expected) {
+		t.Fatalf("Expected %d duty groups, got %d", len(expected), len(result.Duties))
+	}
+
+	for i, group := range result.Duties {
+		if len(group.Duties) != len(expected[i].violated) {
+			t.Fatalf("Expected %d duties in group %d, got %d", len(expected[i].violated), i, len(group.Duties))
+		}
+
+		for j, duty := range group.Duties {
+			if duty.Duty.Identifier != expected[i].identifier {
+				t.Errorf("Expected duty %s in group %d, got %s", expected[i].identifier, i, duty.Duty.Identifier)
+			}
+
+			if duty.Violated != expected[i].violated[j] {
+				t.Errorf("Expected violated to be %t for %s in group %d", expected[i].violated[j], duty.Duty.Identifier, i)
+			}
+		}
+	}
+
+	// Duties can be extended with effects, and triggering one does not check
+	// whether it is enabled like it does for acts
+	body := `{"version": "0.1.0", "kind": "phrases", "phrases": [
+		{"kind": "afact", "name": "buyer", "type": "String"},
+		{"kind": "afact", "name": "seller", "type": "String"},
+		{"kind": "cfact", "name": "paid", "identified-by": ["buyer"]},
+		{"kind": "duty", "name": "pay", "holder": "buyer", "claimant": "seller"},
+		{"kind": "extend", "parent-kind": "duty", "name": "pay", "creates": [{"identifier": "paid", "operands": [["buyer"]]}]},
+		{"kind": "trigger", "operand": {"identifier": "pay", "operands": ["Alice", "Chloe"]}},
+		{"kind": "bquery", "expression": {"identifier": "paid", "operands": ["Alice"]}}
+	]}`
+
+	var output struct {
+		Success bool                     `json:"success"`
+		Results []map[string]interface{} `json:"results"`
+	}
+	response := post(t, "/", body, &output)
+
+	if !output.Success || len(output.Results) != 7 {
+		t.Fatalf("Expected a result for every phrase, got %s", response.Body.String())
+	}
+
+	if trigger := output.Results[5]; trigger["violated"] != false {
+		t.Errorf("Expected triggering pay(Alice, Chloe) not to violate anything, got %v", trigger)
+	}
+
+	if query := output.Results[6]; query["result"] != true {
+		t.Errorf("Expected pay(Alice, Chloe) to create paid(Alice), got %v", query)
+	}
+}
+
+func TestGraph(t *testing.T) {
+	// Request the dependency graph instead of the phrase results
+	input := readRequest(t, "tests/reports/graph.eflint")
+	input["kind"] = "graph"
+
+	var result struct {
+		Success bool         `json:"success"`
+		Graph   eflint.Graph `json:"graph"`
+		Dot     string       `json:"dot"`
+	}
+	post(t, "/", input, &result)
+
+	if !result.Success {
+		t.Fatal("Expected success to be true")
+	}
+
+	nodes := make([]string, 0)
+	for _, node := range result.Graph.Nodes {
+		nodes = append(nodes, node.Name+" "+node.Kind)
+	}
+
+	expectedNodes := []string{"ballot fact", "citizen fact", "election event", "eligible fact", "register act", "registered fact", "vote act", "vote-duty duty"}
+	if strings.Join(nodes, ", ") != strings.Join(expectedNodes, ", ") {
+		t.Errorf("Expected nodes %v, got %v", expectedNodes, nodes)
+	}
+
+	edges := make([]string, 0)
+	for _, edge := range result.Graph.Edges {
+		edges = append(edges, edge.From+" -"+edge.Kind+"-> "+edge.To)
+	}
+
+	expectedEdges := []string{
+		"citizen -actor-> register",
+		"citizen -actor-> vote",
+		"citizen -claimant-> vote-duty",
+		"citizen -holder-> vote-duty",
+		"election -syncs-with-> vote",
+		"eligible -derives-> vote",
+		"eligible -violates-> vote-duty",
+		"register -creates-> registered",
+		"registered -derives-> eligible",
+		"vote -creates-> ballot",
+		"vote -terminates-> vote-duty",
+	}
+	if strings.Join(edges, "\n") != strings.Join(expectedEdges, "\n") {
+		t.Errorf("Expected edges:\n%s\nGot:\n%s", strings.Join(expectedEdges, "\n"), strings.Join(edges, "\n"))
+	}
+
+	if !strings.Contains(result.Dot, `"vote" -> "ballot" [label="creates"];`) {
+		t.Errorf("Expected the DOT output to contain the creates edge of vote, got:\n%s", result.Dot)
+	}
+}
+
+func TestWebSocket(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(websocketHandler))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	dial := func(url string) *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+
+	send := func(conn *websocket.Conn, src string) {
+		_, data := compile(t, "session.eflint", src)
+		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type message struct {
+		Kind    string                 `json:"kind"`
+		Session string                 `json:"session"`
+		Index   int                    `json:"index"`
+		Result  map[string]interface{} `json:"result"`
+		Error   string                 `json:"error"`
+	}
+
+	receive := func(conn *websocket.Conn, kind string) message {
+		var m message
+		if err := conn.ReadJSON(&m); err != nil {
+			t.Fatal(err)
+		}
+		if m.Kind != kind {
+			t.Fatalf("Expected a %s message, got %+v", kind, m)
+		}
+		return m
+	}
+
+	first := dial(url)
+	defer first.Close()
+
+	id := receive(first, "session").Session
+
+	// Every phrase is streamed as soon as it has been run
+	send(first, "Fact citizen Identified by String. +citizen(Alice).")
+	receive(first, "result")
+	if m := receive(first, "result"); m.Index != 1 || len(m.Result["changes"].([]interface{})) != 1 {
+		t.Errorf("Expected the creation of citizen(Alice) as the result of phrase 1, got %+v", m)
+	}
+	receive(first, "done")
+
+	// A second connection to the same session sees the same state, and the
+	// results of its phrases are pushed to the first connection
+	second := dial(url + "?session=" + id)
+	defer second.Close()
+
+	if m := receive(second, "session"); m.Session != id {
+		t.Fatalf("Expected session %s, got %s", id, m.Session)
+	}
+
+	send(second, "?citizen(Alice).")
+	if m := receive(second, "result"); m.Result["result"] != true {
+		t.Errorf("Expected citizen(Alice) to hold in the session, got %+v", m)
+	}
+	receive(second, "done")
+
+	if m := receive(first, "result"); m.Result["result"] != true {
+		t.Errorf("Expected the query result to be pushed to the first connection, got %+v", m)
+	}
+
+	// When a phrase fails, the changes of the earlier phrases are discarded,
+	// and both connections are told to roll back the results they received
+	send(first, "+citizen(Bob). +citizen(5).")
+	if m := receive(first, "result"); m.Index != 0 {
+		t.Errorf("Expected the result of phrase 0 before the failure, got %+v", m)
+	}
+	receive(first, "rollback")
+	if m := receive(first, "error"); !strings.Contains(m.Error, "interpreter failed") {
+		t.Errorf("Expected the interpreter to fail, got %+v", m)
+	}
+
+	receive(second, "result")
+	receive(second, "rollback")
+
+	send(second, "?citizen(Bob).")
+	if m := receive(second, "result"); m.Result["result"] != false {
+		t.Errorf("Expected the creation of citizen(Bob) to be discarded, got %+v", m)
+	}
+	receive(second, "done")
+
+	if m := receive(first, "result"); m.Result["result"] != false {
+		t.Errorf("Expected the query result to be pushed to the first connection, got %+v", m)
+	}
+
+	// A new session starts from an empty state
+	third := dial(url)
+	defer third.Close()
+
+	if m := receive(third, "session"); m.Session == id {
+		t.Fatal("Expected a new session")
+	}
+
+	send(third, "?citizen(Alice).")
+	if m := receive(third, "result"); m.Result["result"] != false {
+		t.Errorf("Expected citizen(Alice) not to hold in a new session, got %+v", m)
+	}
+	receive(third, "done")
+
+	if _, _, err := websocket.DefaultDialer.Dial(url+"?session=unknown", nil); err == nil {
+		t.Error("Expected connecting to an unknown session to fail")
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	// Without a writer, the queue fills up and the outbox closes instead of
+	// holding up the sender
+	out := newOutbox()
+	for i := 0; i < connectionBuffer; i++ {
+		if err := out.push(func() error { return nil }); err != nil {
+			t.Fatalf("Expected write %d to be queued, got %v", i, err)
+		}
+	}
+
+	if err := out.push(func() error { return nil }); err != errSlowConnection {
+		t.Errorf("Expected a full outbox to be slow, got %v", err)
+	}
+	if err := out.push(func() error { return nil }); err != errConnectionClosed {
+		t.Errorf("Expected the outbox to be closed, got %v", err)
+	}
+
+	// Writes are done in order, and flushing waits for them
+	out = newOutbox()
+	defer out.close()
+	go out.run()
+
+	written := make([]int, 0)
+	for i := 0; i < 3; i++ {
+		i := i
+		out.push(func() error { written = append(written, i); return nil })
+	}
+	out.flush()
+
+	if fmt.Sprint(written) != "[0 1 2]" {
+		t.Errorf("Expected the writes in order, got %v", written)
+	}
+}
+
+func TestSubscriptions(t *testing.T) {
+	webhookBackoff = 10 * time.Millisecond
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", websocketHandler)
+	mux.HandleFunc("/sessions/", sessionsHandler)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// The webhook fails the first time, so the event has to be retried
+	received := make(chan map[string]interface{}, 10)
+	attempts := 0
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			http.Error(w, "Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		var e map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&e)
+		received <- e
+	}))
+	defer webhook.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var session struct {
+		Session string `json:"session"`
+	}
+	if err := conn.ReadJSON(&session); err != nil {
+		t.Fatal(err)
+	}
+
+	base := server.URL + "/sessions/" + session.Session
+
+	// Webhooks must be public unless their host is allowed
+	for _, target := range []string{webhook.URL, "http://169.254.169.254/latest/meta-data", "http://10.0.0.1/", "http://[::1]:8080/", "http://0.0.0.0/"} {
+		response, err := http.Post(base+"/subscriptions", "application/json", strings.NewReader(`{"webhook": "`+target+`"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected the webhook %s to be rejected, got status %d", target, response.StatusCode)
+		}
+	}
+
+	webhookHosts = []string{"127.0.0.1"}
+	defer func() { webhookHosts = nil }()
+
+	subscription := `{"facts": ["citizen"], "changes": ["terminate"], "webhook": "` + webhook.URL + `"}`
+	response, err := http.Post(base+"/subscriptions", "application/json", strings.NewReader(subscription))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", response.StatusCode)
+	}
+
+	response, _ = http.Post(base+"/subscriptions", "application/json", strings.NewReader(`{"changes": ["update"], "webhook": "`+webhook.URL+`"}`))
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected an unknown change to be rejected, got status %d", response.StatusCode)
+	}
+
+	stream, err := http.Get(base + "/events?violations=duty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+
+	enabledStream, err := http.Get(base + "/events?changes=enabled")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enabledStream.Body.Close()
+
+	_, data := compile(t, "session.eflint", `
+		Fact citizen Identified by String.
+		Act vote Actor citizen Holds when citizen.
+		+citizen(Alice).
+		-citizen(Alice).
+		Duty pay Holder citizen Claimant citizen Violated when True.
+		+pay(Alice, Bob).
+		+citizen(Carol).
+		?citizen(Carol).
+		+pay(Bob, Alice).
+	`)
+	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case e := <-received:
+		if e["kind"] != "terminate" || e["fact"] != "citizen" {
+			t.Errorf("Expected the termination of citizen, got %v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected an event at the webhook")
+	}
+
+	// Only the violations of the duty are streamed, each once when it starts
+	scanner := bufio.NewScanner(stream.Body)
+	lines := make([]string, 0)
+	for len(lines) < 5 && scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if len(lines) != 5 || lines[0] != "event: violation" || !strings.Contains(lines[1], `"violation":"duty","fact":"pay"`) {
+		t.Errorf("Expected the violation of pay, got %v", lines)
+	} else if lines[3] != "event: violation" || strings.Index(lines[4], "Bob") > strings.Index(lines[4], "Alice") {
+		t.Errorf("Expected the violation of pay by Bob next, got %v", lines[3:])
+	}
+
+	// Voting becomes enabled when Alice becomes a citizen
+	scanner = bufio.NewScanner(enabledStream.Body)
+	lines = make([]string, 0)
+	for len(lines) < 2 && scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if len(lines) != 2 || lines[0] != "event: enabled" || !strings.Contains(lines[1], `"kind":"enabled","fact":"vote"`) || !strings.Contains(lines[1], "Alice") {
+		t.Errorf("Expected vote to become enabled for Alice, got %v", lines)
+	}
+
+	// The streams keep the session alive after the connection has closed
+	conn.Close()
+	s := openSession(session.Session)
+	for i := 0; i < 100; i++ {
+		sessionsLock.Lock()
+		connections := len(s.connections)
+		s.lastUsed = time.Now().Add(-2 * sessionTimeout)
+		sessionsLock.Unlock()
+
+		if connections == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	removeExpiredSessions()
+	if openSession(session.Session) == nil {
+		t.Error("Expected the session to be kept while it has event streams")
+	}
+
+	if len(received) != 0 {
+		t.Errorf("Expected a single event at the webhook, got another one: %v", <-received)
+	}
+}
+
+func TestGRPC(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	server := newGRPCServer()
+	go server.Serve(listener)
+	defer server.Stop()
+
+	dialer := func(ctx context.Context, address string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}
+
+	conn, err := grpc.Dial("bufnet", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client := eflintpb.NewReasonerClient(conn)
+	ctx := context.Background()
+
+	input := func(src string) *eflintpb.Input {
+		_, data := compile(t, "grpc.eflint", src)
+
+		var input eflint.Input
+		if err := json.Unmarshal(data, &input); err != nil {
+			t.Fatal(err)
+		}
+		return inputToProto(input)
+	}
+
+	handshake, err := client.Handshake(ctx, &eflintpb.HandshakeRequest{Version: eflint.SupportedVersions()[0]})
+	if err != nil || handshake.GetReasoner() != eflint.Reasoner {
+		t.Errorf("Expected the handshake of the reasoner, got %v (%v)", handshake, err)
+	}
+
+	if _, err := client.Ping(ctx, &eflintpb.PingRequest{Version: "0.0.0"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected an unsupported version to be rejected, got %v", err)
+	}
+
+	output, err := client.Phrases(ctx, input("Fact citizen Identified by String. +citizen(Alice). ?citizen(Alice)."))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := output.GetResults()
+	if len(results) != 3 || results[2].GetKind() != eflintpb.PhraseResult_BQUERY || !results[2].GetResult() {
+		t.Errorf("Expected citizen(Alice) to hold, got %v", results)
+	}
+
+	inspected, err := client.Inspect(ctx, &eflintpb.InspectRequest{Version: eflint.SupportedVersions()[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a session, nothing of the earlier call is inspected
+	if len(inspected.GetInstances()) != 0 || len(inspected.GetResults()) != 0 {
+		t.Errorf("Expected nothing to inspect without a session, got %v", inspected)
+	}
+
+	ping := input("")
+	ping.Kind = "ping"
+	if _, err := client.Phrases(ctx, ping); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected Phrases to reject pings, got %v", err)
+	}
+
+	receive := func(stream eflintpb.Reasoner_SessionClient, kind string) *eflintpb.SessionMessage {
+		m, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.GetKind() != kind {
+			t.Fatalf("Expected a %s message, got %v", kind, m)
+		}
+		return m
+	}
+
+	first, err := client.Session(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := receive(first, "session").GetSession()
+
+	if err := first.Send(input("Fact citizen Identified by String. +citizen(Bob).")); err != nil {
+		t.Fatal(err)
+	}
+	receive(first, "result")
+	if m := receive(first, "result"); m.GetIndex() != 1 || len(m.GetResult().GetChanges()) != 1 {
+		t.Errorf("Expected the creation of citizen(Bob) as the result of phrase 1, got %v", m)
+	}
+	receive(first, "done")
+
+	// A second stream joins the session through metadata
+	second, err := client.Session(metadata.AppendToOutgoingContext(ctx, sessionMetadata, id))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m := receive(second, "session"); m.GetSession() != id {
+		t.Fatalf("Expected to join session %s, got %s", id, m.GetSession())
+	}
+
+	if err := second.Send(input("?citizen(Bob).")); err != nil {
+		t.Fatal(err)
+	}
+	if m := receive(second, "result"); !m.GetResult().GetResult() {
+		t.Errorf("Expected citizen(Bob) to hold in the session, got %v", m)
+	}
+	receive(first, "result")
+	receive(second, "done")
+
+	inspected, err = client.Inspect(metadata.AppendToOutgoingContext(ctx, sessionMetadata, id), &eflintpb.InspectRequest{Version: eflint.SupportedVersions()[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if instances := inspected.GetInstances(); len(instances) != 1 || instances[0].GetIdentifier() != "citizen" {
+		t.Errorf("Expected to inspect citizen(Bob) in the session, got %v", instances)
+	}
+
+	if _, err := client.Inspect(metadata.AppendToOutgoingContext(ctx, sessionMetadata, "nosuch"), &eflintpb.InspectRequest{Version: eflint.SupportedVersions()[0]}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected an unknown session to be rejected, got %v", err)
+	}
+
+	first.CloseSend()
+	second.CloseSend()
+}
+
+func TestClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", eFLINTHandler)
+	mux.HandleFunc("/ws", websocketHandler)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+	c := client.New(server.URL)
+
+	handshake, err := c.Handshake(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newest := eflint.SupportedVersions()[len(eflint.SupportedVersions())-1]; handshake.Reasoner != eflint.Reasoner || c.Version != newest {
+		t.Errorf("Expected to negotiate version %s, got %s from %+v", newest, c.Version, handshake)
+	}
+	if len(c.Extensions) != 1 || c.Extensions[0] != eflint.ResultKindExtension {
+		t.Errorf("Expected to ask for the result-kind extension, got %v", c.Extensions)
+	}
+
+	if err := c.Ping(ctx); err != nil {
+		t.Error(err)
+	}
+
+	alice := client.Apply("citizen", client.String("Alice"))
+	declaration := client.AtomicFact("citizen", "String")
+
+	output, err := c.Phrases(ctx, declaration, client.Create(alice), client.BQuery(alice), client.IQuery(client.Var("citizen")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(output.Results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(output.Results))
+	}
+	if changes, ok := output.Results[1].(client.StateChanges); !ok || len(changes.Changes) != 1 || changes.Changes[0].Operand.Name() != "citizen" {
+		t.Errorf("Expected the creation of citizen(Alice), got %+v", output.Results[1])
+	}
+	if bquery, ok := output.Results[2].(client.BQueryResult); !ok || !bquery.Result {
+		t.Errorf("Expected citizen(Alice) to hold, got %+v", output.Results[2])
+	}
+	if iquery, ok := output.Results[3].(client.IQueryResult); !ok || len(iquery.Result) != 1 {
+		t.Errorf("Expected a single citizen, got %+v", output.Results[3])
+	}
+
+	var requestErr *client.RequestError
+	if _, err := c.Do(ctx, client.Input{Version: "0.0.0", Kind: "ping"}); !errors.As(err, &requestErr) ||
+		!requestErr.HasCode(client.CodeUnsupportedVersion) || requestErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected an unsupported version to fail the request, got %v", err)
+	}
+
+	first, err := c.Session(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+
+	if results, _, err := first.Phrases(declaration, client.Create(alice)); err != nil || len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %+v (%v)", results, err)
+	}
+
+	second, err := c.Session(ctx, first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	results, _, err := second.Phrases(client.BQuery(alice))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bquery, ok := results[0].(client.BQueryResult); !ok || !bquery.Result {
+		t.Errorf("Expected citizen(Alice) to hold in the session, got %+v", results[0])
+	}
+
+	instances, err := second.Inspect()
+	if err != nil || len(instances) != 1 || instances[0].Name() != "citizen" {
+		t.Errorf("Expected to inspect citizen(Alice) in the session, got %+v (%v)", instances, err)
+	}
+
+	// The first connection receives the result of the second
+	if m, err := first.Receive(); err != nil || m.Kind != "result" {
+		t.Errorf("Expected the result of the second connection, got %+v (%v)", m, err)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	// An older version of the specification that calls phrases statements,
+	// and the result of a query its answer
+	unregister := eflint.RegisterProtocol(eflint.Protocol{
+		Version: "0.0.5",
+		Upgrade: func(input map[string]interface{}) error {
+			if statements, ok := input["statements"]; ok {
+				input["phrases"] = statements
+				delete(input, "statements")
+			}
+			return nil
+		},
+		Downgrade: func(result map[string]interface{}) error {
+			if answer, ok := result["result"]; ok {
+				result["answer"] = answer
+				delete(result, "result")
+			}
+			return nil
+		},
+	})
+	defer unregister()
+
+	handshakes := []struct {
+		version  string
+		expected string
+	}{
+		{"0.1.0", "0.1.0"},
+		{"^0.1.0", "0.1.0"},
+		{">=0.1.0", "0.1.0"},
+		{"0.0.x", "0.0.5"},
+		{">=0.0.1 <0.1.0", "0.0.5"},
+		{">=0.0.1, <0.1.0", "0.0.5"},
+		{">=0.1.0,<0.2.0", "0.1.0"},
+		{"1.x || ~0.0.4", "0.0.5"},
+		{"*", "0.1.0"},
+	}
+
+	for _, handshake := range handshakes {
+		var output map[string]interface{}
+		post(t, "/", fmt.Sprintf(`{"version": %q, "kind": "handshake"}`, handshake.version), &output)
+		if output["success"] != true || output["version"] != handshake.expected {
+			t.Errorf("Expected version %s for %s, got %v", handshake.expected, handshake.version, output)
+		}
+		if extensions, _ := output["extensions"].([]interface{}); len(extensions) != 1 || extensions[0] != eflint.ResultKindExtension {
+			t.Errorf("Expected the handshake to list the result-kind extension, got %v", output)
+		}
+	}
+
+	for _, version := range []string{"2.x", "0.1.1", "0.2.0", "<0.0.5", "latest", ">=0.1.0; <0.2.0", ""} {
+		var output map[string]interface{}
+		post(t, "/", fmt.Sprintf(`{"version": %q, "kind": "handshake"}`, version), &output)
+		errs, _ := output["errors"].([]interface{})
+		if output["success"] != false || len(errs) != 1 {
+			t.Errorf("Expected version %q to be rejected, got %v", version, output)
+			continue
+		}
+
+		err := errs[0].(map[string]interface{})
+		if err["id"] != "version" || !strings.HasSuffix(err["message"].(string), "the supported versions are 0.0.5, 0.1.0") {
+			t.Errorf("Expected the supported versions for %q, got %v", version, err)
+		}
+		if message := err["message"].(string); strings.Contains(message, "empty version") {
+			t.Errorf("Expected the reason that %q is rejected, got %s", version, message)
+		}
+	}
+
+	phrases := `[{"kind": "afact", "name": "citizen", "type": "String"}, {"kind": "create", "operand": {"identifier": "citizen", "operands": ["Alice"]}}, {"kind": "bquery", "expression": {"identifier": "citizen", "operands": ["Alice"]}}]`
+
+	// Results only name their kind with the result-kind extension, in any
+	// version
+	inputs := []struct {
+		input string
+		field string
+		kind  interface{}
+	}{
+		{`{"version": "0.0.5", "kind": "phrases", "statements": ` + phrases + `}`, "answer", nil},
+		{`{"version": "0.1.0", "kind": "phrases", "phrases": ` + phrases + `}`, "result", nil},
+		{`{"version": "*", "kind": "phrases", "phrases": ` + phrases + `}`, "result", nil},
+		{`{"version": "0.1.0", "kind": "phrases", "phrases": ` + phrases + `, "extensions": ["result-kind"]}`, "result", "bquery"},
+		{`{"version": "0.0.5", "kind": "phrases", "statements": ` + phrases + `, "extensions": ["result-kind"]}`, "answer", "bquery"},
+	}
+
+	for _, input := range inputs {
+		var output map[string]interface{}
+		post(t, "/", input.input, &output)
+		results, _ := output["results"].([]interface{})
+		if output["success"] != true || len(results) != 3 {
+			t.Fatalf("Expected 3 results for %s, got %v", input.input, output)
+		}
+
+		if results[2].(map[string]interface{})[input.field] != true {
+			t.Errorf("Expected the query to hold in field %s, got %v", input.field, results[2])
+		}
+		if kind := results[1].(map[string]interface{})["kind"]; input.kind != nil && kind != "changes" || input.kind == nil && kind != nil {
+			t.Errorf("Expected the kind of the changes only if it is %v, got %v", input.kind, results[1])
+		}
+		if kind := results[2].(map[string]interface{})["kind"]; kind != input.kind {
+			t.Errorf("Expected kind %v for the query, got %v", input.kind, results[2])
+		}
+	}
+
+	// Sessions send results in the version of the last input of a connection
+	server := httptest.NewServer(http.HandlerFunc(websocketHandler))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(inputs[0].input)); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		var m map[string]interface{}
+		if err := conn.ReadJSON(&m); err != nil {
+			t.Fatal(err)
+		}
+
+		if m["kind"] == "result" && m["index"] == 2.0 {
+			if m["result"].(map[string]interface{})["answer"] != true {
+				t.Errorf("Expected the query to hold in field answer, got %v", m)
+			}
+			return
+		}
+	}
+
+	t.Error("Expected the result of the query")
+}
+
+func TestOpenAPI(t *testing.T) {
+	request, _ := http.NewRequest("GET", openAPIPath, nil)
+	response := httptest.NewRecorder()
+
+	openAPIHandler(response, request)
+
+	var document struct {
+		OpenAPI    string                 `json:"openapi"`
+		Paths      map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(document.OpenAPI, "3.") {
+		t.Errorf("Expected an OpenAPI 3 document, got version %s", document.OpenAPI)
+	}
+	for _, path := range []string{"/", "/ws", "/sessions/{id}/events", openAPIPath} {
+		if _, ok := document.Paths[path]; !ok {
+			t.Errorf("Expected path %s to be described", path)
+		}
+	}
+	for _, schema := range []string{"Input", "Phrase", "Expression", "Output", "Handshake"} {
+		if _, ok := document.Components.Schemas[schema]; !ok {
+			t.Errorf("Expected schema %s to be described", schema)
+		}
+	}
+
+	inputs := []struct {
+		input    string
+		expected []eflint.Error
+	}{
+		{
+			`{"version": "0.1.0", "kind": "phrases", "phrases": [{"kind": "afact", "type": "String"}, {"kind": "create", "operand": {"identifier": "citizen", "operands": [1.5]}}, {"kind": "bquery", "expresion": "x"}]}`,
+			[]eflint.Error{
+				{Pointer: "/phrases/0/name", Message: "missing required field"},
+				{Pointer: "/phrases/1/operand/operands/0", Message: "expected one of string, integer, boolean, array, object, got a number"},
+				{Pointer: "/phrases/2/expression", Message: "missing required field"},
+				{Pointer: "/phrases/2/expresion", Message: "unknown field"},
+			},
+		},
+		{
+			`{"version": "0.1.0", "kind": "explain"}`,
+			[]eflint.Error{{Pointer: "/kind", Message: `unknown value "explain", expected one of duties, explore, graph, handshake, inspect, phrases, ping`}},
+		},
+		{
+			`{"version": "0.1.0", "kind": "explore", "phrases": [], "depth": -1}`,
+			[]eflint.Error{{Pointer: "/depth", Message: "expected at least 0, got -1"}},
+		},
+		{
+			`{"version": "0.1.0", "kind": "ping", "phrases": []}`,
+			[]eflint.Error{{Pointer: "/phrases", Message: "unknown field"}},
+		},
+		{
+			`{"version": "0.1.0", "kind": "phrases", "phrases": [{"kind": "bquery", "expression": {"identifier": "citizen", "operands": [["x", "y"]]}}]}`,
+			[]eflint.Error{{Pointer: "/phrases/0/expression/operands/0", Message: "expected at most 1 item, got 2"}},
+		},
+	}
+
+	for _, input := range inputs {
+		var output eflint.Output
+		post(t, "/", input.input, &output)
+
+		if output.Success || len(output.Errors) != len(input.expected) {
+			t.Errorf("Expected %d errors for %s, got %+v", len(input.expected), input.input, output)
+			continue
+		}
+
+		for i, err := range output.Errors {
+			if err.Id != "validation" || err.Pointer != input.expected[i].Pointer || err.Message != input.expected[i].Message {
+				t.Errorf("Expected %s: %s, got %+v", input.expected[i].Pointer, input.expected[i].Message, err)
+			}
+		}
+	}
+
+	// Malformed JSON has no field to point to
+	var output eflint.Output
+	post(t, "/", `{"version": `, &output)
+	if output.Success || len(output.Errors) != 1 || !strings.HasPrefix(output.Errors[0].Message, "invalid JSON") {
+		t.Errorf("Expected an invalid JSON error, got %+v", output)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	requests := []struct {
+		method  string
+		body    string
+		status  int
+		code    string
+		pointer string
+	}{
+		{"GET", ``, http.StatusMethodNotAllowed, codeMethodNotAllowed, ""},
+		{"POST", `{"version": `, http.StatusBadRequest, codeInvalidJSON, ""},
+		{"POST", `{"version": "0.1.0"}`, http.StatusBadRequest, codeMissingField, "/kind"},
+		{"POST", `{"version": "0.1.0", "kind": "explain"}`, http.StatusBadRequest, codeUnknownKind, "/kind"},
+		{"POST", `{"version": "0.1.0", "kind": "phrases", "phrases": [{"kind": "fly"}]}`, http.StatusBadRequest, codeUnknownKind, "/phrases/0/kind"},
+		{"POST", `{"version": "0.1.0", "kind": "ping", "updates": true}`, http.StatusBadRequest, codeUnknownField, "/updates"},
+		{"POST", `{"version": "0.1.0", "kind": "phrases", "phrases": [{"kind": "bquery", "expression": {"identifier": 5}}]}`, http.StatusBadRequest, codeMalformedExpression, "/phrases/0/expression/identifier"},
+		{"POST", `{"version": "0.1.0", "kind": "phrases", "phrases": [{"kind": "create", "operand": 1.5}]}`, http.StatusBadRequest, codeMalformedExpression, "/phrases/0/operand"},
+		{"POST", `{"version": "0.1.0", "kind": "phrases", "phrases": [{"kind": "create", "operand": {"identifier": "age", "operands": [5.5]}}]}`, http.StatusBadRequest, codeMalformedExpression, "/phrases/0/operand/operands/0"},
+		{"POST", `{"version": "0.1.0", "kind": "explore", "phrases": [], "depth": "deep"}`, http.StatusBadRequest, codeInvalidValue, "/depth"},
+		{"POST", `{"version": "0.1.0", "kind": "explore", "phrases": [], "goal": {"identifier": "nosuch", "operands": []}}`, http.StatusUnprocessableEntity, codeTypecheckFailed, "/goal"},
+		{"POST", `{"version": "9.9.9", "kind": "ping"}`, http.StatusUnprocessableEntity, codeUnsupportedVersion, "/version"},
+		{"POST", `{"version": "0.1.0", "kind": "ping", "extensions": ["result-kinds"]}`, http.StatusBadRequest, codeInvalidValue, "/extensions/0"},
+		{"POST", `{"version": "0.1.0", "kind": "phrases", "phrases": [{"kind": "bquery", "expression": {"identifier": "nosuch", "operands": []}}]}`, http.StatusUnprocessableEntity, codeInterpreterError, ""},
+	}
+
+	for _, r := range requests {
+		request, _ := http.NewRequest(r.method, "/", strings.NewReader(r.body))
+		response := httptest.NewRecorder()
+
+		eFLINTHandler(response, request)
+
+		if response.Code != r.status || response.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("Expected status %d with JSON for %s, got %d with %s", r.status, r.body, response.Code, response.Header().Get("Content-Type"))
+		}
+
+		var output eflint.Output
+		if err := json.Unmarshal(response.Body.Bytes(), &output); err != nil {
+			t.Fatal(err)
+		}
+
+		if output.Success || len(output.Errors) == 0 {
+			t.Errorf("Expected errors for %s, got %+v", r.body, output)
+			continue
+		}
+
+		if err := output.Errors[0]; err.Code != r.code || err.Pointer != r.pointer || err.Message == "" {
+			t.Errorf("Expected code %s at %q for %s, got %+v", r.code, r.pointer, r.body, err)
+		}
+	}
+}
+
+func TestExplore(t *testing.T) {
+	// Search for violations and for a state in which Bob has paid
+	input := readRequest(t, "tests/reports/explore.eflint")
+	input["kind"] = "explore"
+	input["depth"] = 2
+	input["goal"] = map[string]interface{}{"identifier": "paid", "operands": []string{"Bob"}}
+
+	var result struct {
+		Success bool `json:"success"`
+		Traces  []struct {
+			Kind    string `json:"kind"`
+			Phrases []struct {
+				Operand struct {
+					Identifier string `json:"identifier"`
+				} `json:"operand"`
+			} `json:"phrases"`
+			Violations []struct {
+				Kind       string `json:"kind"`
+				Identifier string `json:"identifier"`
+			} `json:"violations"`
+		} `json:"traces"`
+	}
+	post(t, "/", input, &result)
+
+	if !result.Success {
+		t.Fatal("Expected success to be true")
+	}
+
+	violations, goals := 0, 0
+
+	for _, trace := range result.Traces {
+		if len(trace.Phrases) != 2 {
+			t.Errorf("Expected traces of length 2, got %d", len(trace.Phrases))
+		}
+
+		switch trace.Kind {
+		case "violation":
+			violations++
+			if len(trace.Violations) != 1 || trace.Violations[0].Identifier != "pay-fee" {
+				t.Errorf("Expected pay-fee to be violated, got %v", trace.Violations)
+			}
+		case "goal":
+			goals++
+			if trace.Phrases[1].Operand.Identifier != "pay" {
+				t.Errorf("Expected the goal to be reached by pay, got %s", trace.Phrases[1].Operand.Identifier)
+			}
+		default:
+			t.Errorf("Unexpected trace kind %s", trace.Kind)
+		}
+	}
+
+	if violations != 4 || goals != 1 {
+		t.Fatalf("Expected 4 violations and 1 goal, got %d and %d", violations, goals)
+	}
+
+	// Deep explorations are cut off, and so are explorations of many states:
+	// with only the initial state, nothing is found in two steps
+	defer func(depth int, states int) {
+		eflint.MaxExploreDepth, eflint.MaxExploreStates = depth, states
+	}(eflint.MaxExploreDepth, eflint.MaxExploreStates)
+
+	for _, limits := range []struct {
+		depth, states, maxDepth, traces int
+	}{
+		{1000000, eflint.MaxExploreStates, 2, 5},
+		{2, 1, eflint.MaxExploreDepth, 0},
+	} {
+		input["depth"] = limits.depth
+		eflint.MaxExploreDepth, eflint.MaxExploreStates = limits.maxDepth, limits.states
+
+		result.Traces = nil
+		response := post(t, "/", input, &result)
+
+		if !result.Success || len(result.Traces) != limits.traces {
+			t.Errorf("Expected %d traces with depth %d and %d states, got %s", limits.traces, limits.depth, limits.states, response.Body.String())
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	_, data := compile(t, "metrics.eflint", `
+		Fact citizen Identified by String.
+		+citizen(Alice).
+		Duty pay Holder citizen Claimant citizen Violated when True.
+		+pay(Alice, Bob).
+	`)
+
+	for _, body := range []string{string(data), `{"version": "0.1.0", "kind": "fly"}`} {
+		post(t, "/", body, nil)
+	}
+
+	request, _ := http.NewRequest("GET", metricsPath, nil)
+	response := httptest.NewRecorder()
+
+	metricsHandler.ServeHTTP(response, request)
+
+	if !strings.HasPrefix(response.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("Expected the text format, got %s", response.Header().Get("Content-Type"))
+	}
+
+	for _, metric := range []string{
+		`eflint_requests_total{kind="phrases",transport="http"}`,
+		`eflint_requests_total{kind="invalid",transport="http"}`,
+		`eflint_request_duration_seconds_bucket{kind="phrases",transport="http",le="+Inf"}`,
+		`eflint_phrases_total{kind="duty"}`,
+		`eflint_phrases_total{kind="create"}`,
+		`eflint_derivation_iterations_count{strategy="DeriveFacts3"}`,
+		`eflint_derivation_duration_seconds_count{strategy="DeriveFacts3"}`,
+		`eflint_violations_total{kind="duty"}`,
+		`eflint_sessions_active`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(response.Body.String(), "\n"+metric+" ") {
+			t.Errorf("Expected metric %s", metric)
+		}
+	}
+}
+
+func TestLogging(t *testing.T) {
+	var out bytes.Buffer
+	logger = logging.New(&out, logging.LevelDebug, logging.FormatJSON)
+	eflint.SetLogger(logger)
+	eflint.SetAudit(true)
+	defer func() {
+		if err := configureLogging("info", "logfmt", false); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	_, data := compile(t, "logging.eflint", `
+		Fact citizen Identified by String.
+		Placeholder person For citizen.
+		+citizen(Alice).
+	`)
+
+	for _, body := range []string{string(data), `{"version": "0.1.0", "kind": "fly"}`} {
+		request, _ := http.NewRequest("POST", "/", strings.NewReader(body))
+		request.Header.Set(requestIDHeader, "request-1")
+		response := httptest.NewRecorder()
+
+		eFLINTHandler(response, request)
+
+		if id := response.Header().Get(requestIDHeader); id != "request-1" {
+			t.Errorf("Expected the request ID to be sent back, got %q", id)
+		}
+	}
+
+	messages := make(map[string]int)
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Expected a JSON line, got %s", line)
+		}
+
+		if entry["request"] != "request-1" || entry["level"] == nil || entry["time"] == nil {
+			t.Errorf("Expected the request ID, level and time on every line, got %s", line)
+		}
+
+		message, _ := entry["msg"].(string)
+		messages[message]++
+
+		if message == "phrase interpreted" {
+			if _, ok := entry["phrase"].(map[string]interface{}); !ok {
+				t.Errorf("Expected the phrase in the audit line, got %s", line)
+			}
+			if _, ok := entry["result"].(map[string]interface{}); !ok {
+				t.Errorf("Expected the result in the audit line, got %s", line)
+			}
+		}
+	}
+
+	expected := map[string]int{
+		"phrase interpreted": 3,
+		"new placeholder":    1,
+		`+citizen("Alice")`:  1,
+		"input rejected":     1,
+		"request handled":    2,
+	}
+	for message, count := range expected {
+		if messages[message] != count {
+			t.Errorf("Expected %d lines with message %q, got %d", count, message, messages[message])
+		}
+	}
+
+	if err := configureLogging("verbose", "logfmt", false); err == nil {
+		t.Error("Expected an unknown log level to be rejected")
+	}
+}
+
+func TestInspect(t *testing.T) {
+	inspect := func(target string, body string) (*httptest.ResponseRecorder, eflint.Output) {
+		var output eflint.Output
+		response := post(t, target, body, &output)
+		return response, output
+	}
+
+	_, data := compile(t, "secret.eflint", `
+		Fact secret Identified by String.
+		+secret("Password123").
+	`)
+	post(t, "/", data, nil)
+
+	// Nothing of another request can be inspected outside a session
+	for _, kind := range []string{"inspect", "ping"} {
+		if _, output := inspect("/", `{"version": "0.1.0", "kind": "`+kind+`"}`); len(output.Instances) != 0 || len(output.Results) != 0 {
+			t.Errorf("Expected an empty %s response, got %+v", kind, output)
+		}
+	}
+
+	var decoded eflint.Input
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	s := openSession("")
+	interpreterLock.Lock()
+	eflint.InterpretPhrases(decoded.Phrases)
+	s.state = eflint.SaveState()
+	interpreterLock.Unlock()
+
+	if _, output := inspect("/?session="+s.id, `{"version": "0.1.0", "kind": "inspect"}`); len(output.Instances) != 1 || output.Instances[0].Identifier != "secret" || len(output.Results) != 0 {
+		t.Errorf("Expected to inspect the instance of the session, got %+v", output)
+	}
+
+	if response, output := inspect("/?session=nosuch", `{"version": "0.1.0", "kind": "inspect"}`); response.Code != http.StatusNotFound || len(output.Errors) != 1 || output.Errors[0].Code != codeUnknownSession {
+		t.Errorf("Expected an unknown session to be rejected, got %d %+v", response.Code, output)
+	}
+}
+
+func TestAggregates(t *testing.T) {
+	var out bytes.Buffer
+	logger = logging.New(&out, logging.LevelWarn, logging.FormatLogfmt)
+	defer func() {
+		if err := configureLogging("info", "logfmt", false); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// The maximum of an empty set has no value, and neither have the
+	// operators around it, so every query fails
+	input, data := compile(t, "aggregates.eflint", `
+		Fact temperature Identified by Int.
+		?Max(Foreach temperature : temperature) == 0.
+		?Max(Foreach temperature : temperature) != 0.
+		?Max(Foreach temperature : temperature) > -1.
+		?Not(Max(Foreach temperature : temperature) == 0).
+		?Avg(Foreach temperature : temperature) == 0 || True.
+		?True && Min(Foreach temperature : temperature) == 0.
+	`)
+
+	var output struct {
+		Success bool                     `json:"success"`
+		Results []map[string]interface{} `json:"results"`
+	}
+	response := post(t, "/", data, &output)
+
+	if !output.Success || len(output.Results) != len(input.Phrases) {
+		t.Fatalf("Expected a result for every phrase, got %s", response.Body.String())
+	}
+
+	for i, result := range output.Results[1:] {
+		if result["result"] != false {
+			t.Errorf("Expected query %d to fail, got %v", i+1, result)
+		}
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("Expected no warnings, got %s", out.String())
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	// Numbers without a fractional part are integers, however they are written
+	body := `{"version": "0.1.0", "kind": "phrases", "phrases": [
+		{"kind": "afact", "name": "age", "type": "Int"},
+		{"kind": "create", "operand": {"identifier": "age", "operands": [5.0]}},
+		{"kind": "create", "operand": {"identifier": "age", "operands": [4e1]}},
+		{"kind": "bquery", "expression": {"identifier": "age", "operands": [5]}},
+		{"kind": "bquery", "expression": {"identifier": "age", "operands": [40]}}
+	]}`
+
+	var output struct {
+		Success bool                     `json:"success"`
+		Results []map[string]interface{} `json:"results"`
+	}
+	response := post(t, "/", body, &output)
+
+	if !output.Success || len(output.Results) != 5 {
+		t.Fatalf("Expected a result for every phrase, got %s", response.Body.String())
+	}
+
+	for i, result := range output.Results[3:] {
+		if result["result"] != true {
+			t.Errorf("Expected query %d to succeed, got %v", i+1, result)
+		}
+	}
+}
+
 func benchmarkDirectoryServer(b *testing.B, path string) {
 	filepath.WalkDir(path, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/openapi"
+)
+
+// The codes of the errors that inputs are rejected with. Unlike the messages,
+// clients can rely on them.
+const (
+	codeInvalidJSON         = "invalid_json"
+	codeMissingField        = "missing_field"
+	codeUnknownField        = "unknown_field"
+	codeInvalidValue        = "invalid_value"
+	codeUnknownKind         = "unknown_kind"
+	codeMalformedExpression = "malformed_expression"
+	codeUnsupportedVersion  = "unsupported_version"
+	codeUnsupportedFields   = "unsupported_fields"
+	codeInvalidDepth        = "invalid_depth"
+	codeTypecheckFailed     = "typecheck_failed"
+	codeMethodNotAllowed    = "method_not_allowed"
+	codeUnknownSession      = "unknown_session"
+	codeInterpreterError    = "interpreter_error"
+)
+
+// codeStatus is the HTTP status of the response to an input that is rejected
+// with an error of the code. Inputs that are not well-formed are bad
+// requests, and well-formed inputs that cannot be run are unprocessable.
+var codeStatus = map[string]int{
+	codeInvalidJSON:         http.StatusBadRequest,
+	codeMissingField:        http.StatusBadRequest,
+	codeUnknownField:        http.StatusBadRequest,
+	codeInvalidValue:        http.StatusBadRequest,
+	codeUnknownKind:         http.StatusBadRequest,
+	codeMalformedExpression: http.StatusBadRequest,
+	codeUnsupportedVersion:  http.StatusUnprocessableEntity,
+	codeUnsupportedFields:   http.StatusUnprocessableEntity,
+	codeInvalidDepth:        http.StatusUnprocessableEntity,
+	codeTypecheckFailed:     http.StatusUnprocessableEntity,
+	codeMethodNotAllowed:    http.StatusMethodNotAllowed,
+	codeUnknownSession:      http.StatusNotFound,
+	codeInterpreterError:    http.StatusUnprocessableEntity,
+}
+
+// expressionSchemas are the schemas of the description that expressions are
+// validated against.
+var expressionSchemas = []string{"Expression", "VariableReference", "ConstructorApplication", "Operator", "Iterator", "Projection"}
+
+// inputError is an input that was rejected, with an error for every reason.
+type inputError struct {
+	errs []eflint.Error
+}
+
+func (e *inputError) Error() string {
+	return errorsMessage(e.errs)
+}
+
+// rejectInput responds to an input that could not be run, with the status of
+// its first error. The results of earlier requests are left out, unlike with
+// GenerateJSON.
+func rejectInput(w http.ResponseWriter, errs []eflint.Error) {
+	output, err := json.Marshal(eflint.Output{Success: false, Errors: errs})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status, ok := codeStatus[errs[0].Code]
+	if !ok {
+		status = http.StatusBadRequest
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(output)
+}
+
+// recoverInterpreter turns a panic of the interpreter into an inputError, so
+// that only the request fails. It must be deferred directly.
+func recoverInterpreter(err *error) {
+	if r := recover(); r != nil {
+		*err = &inputError{[]eflint.Error{{Id: "interpreter", Code: codeInterpreterError, Message: fmt.Sprintf("interpreter failed: %v", r)}}}
+	}
+}
+
+// typecheckError describes why an input was rejected by Typecheck.
+// Unsupported versions are reported with the versions that are supported.
+func typecheckError(err error) eflint.Error {
+	switch {
+	case errors.Is(err, eflint.ErrUnsupportedVersion):
+		return eflint.Error{Id: "version", Code: codeUnsupportedVersion, Message: err.Error(), Pointer: "/version"}
+	case errors.Is(err, eflint.ErrUnsupportedExtension):
+		return eflint.Error{Id: "typecheck", Code: codeInvalidValue, Message: err.Error(), Pointer: "/extensions"}
+	case errors.Is(err, eflint.ErrUnknownKind):
+		return eflint.Error{Id: "typecheck", Code: codeUnknownKind, Message: err.Error(), Pointer: "/kind"}
+	case errors.Is(err, eflint.ErrUnsupportedFields):
+		return eflint.Error{Id: "typecheck", Code: codeUnsupportedFields, Message: err.Error()}
+	case errors.Is(err, eflint.ErrInvalidGoal):
+		return eflint.Error{Id: "typecheck", Code: codeTypecheckFailed, Message: err.Error(), Pointer: "/goal"}
+	case errors.Is(err, eflint.ErrInvalidDepth):
+		return eflint.Error{Id: "typecheck", Code: codeInvalidDepth, Message: err.Error(), Pointer: "/depth"}
+	default:
+		return eflint.Error{Id: "typecheck", Code: codeTypecheckFailed, Message: err.Error()}
+	}
+}
+
+// validationErrors converts the errors of validating an input against the
+// description of the server.
+func validationErrors(errs []openapi.FieldError) []eflint.Error {
+	converted := make([]eflint.Error, 0, len(errs))
+	for _, err := range errs {
+		converted = append(converted, eflint.Error{
+			Id:      "validation",
+			Code:    validationCode(err),
+			Message: err.Message,
+			Pointer: err.Pointer,
+		})
+	}
+
+	return converted
+}
+
+func validationCode(err openapi.FieldError) string {
+	switch {
+	case err.Reason == openapi.ReasonDiscriminator && strings.HasSuffix(err.Pointer, "/kind"):
+		return codeUnknownKind
+	case contains(expressionSchemas, err.Schema):
+		return codeMalformedExpression
+	case err.Reason == openapi.ReasonRequired:
+		return codeMissingField
+	case err.Reason == openapi.ReasonUnknownField:
+		return codeUnknownField
+	default:
+		return codeInvalidValue
+	}
+}
+
+// errorsMessage joins errors into a single message, for responses that have
+// no room for a list.
+func errorsMessage(errs []eflint.Error) string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		if err.Pointer != "" {
+			messages = append(messages, err.Pointer+": "+err.Message)
+			continue
+		}
+		messages = append(messages, err.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflintpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// sessionMetadata is the metadata key that a session stream uses to join an
+// existing session.
+const sessionMetadata = "eflint-session"
+
+// reasonerServer serves the gRPC API, backed by the same interpreter as the
+// JSON endpoint.
+type reasonerServer struct {
+	eflintpb.UnimplementedReasonerServer
+}
+
+func newGRPCServer() *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(observeUnary))
+	eflintpb.RegisterReasonerServer(server, reasonerServer{})
+
+	return server
+}
+
+func (reasonerServer) Phrases(ctx context.Context, in *eflintpb.Input) (output *eflintpb.Output, err error) {
+	input := inputFromProto(in)
+
+	switch input.Kind {
+	case "phrases", "duties", "explore", "graph":
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "kind %s is not supported by Phrases", input.Kind)
+	}
+
+	interpreterLock.Lock()
+	defer interpreterLock.Unlock()
+	defer useLogger(contextLogger(ctx))()
+	defer internalError(&err)
+	defer recoverInterpreter(&err)
+
+	if err := eflint.Typecheck(input); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	eflint.InterpretPhrases(input.Phrases)
+
+	result, err := kindOutput(input)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return outputToProto(eflint.WithResults(result)), nil
+}
+
+func (reasonerServer) Ping(ctx context.Context, in *eflintpb.PingRequest) (*eflintpb.Output, error) {
+	if err := eflint.Typecheck(eflint.Input{Version: in.GetVersion(), Kind: "ping"}); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &eflintpb.Output{Success: true}, nil
+}
+
+func (reasonerServer) Handshake(ctx context.Context, in *eflintpb.HandshakeRequest) (*eflintpb.Handshake, error) {
+	if err := eflint.Typecheck(eflint.Input{Version: in.GetVersion(), Kind: "handshake"}); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return handshakeToProto(eflint.NegotiatedHandshake(in.GetVersion())), nil
+}
+
+func (reasonerServer) Inspect(ctx context.Context, in *eflintpb.InspectRequest) (*eflintpb.Output, error) {
+	if err := eflint.Typecheck(eflint.Input{Version: in.GetVersion(), Kind: "inspect"}); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	interpreterLock.Lock()
+	defer interpreterLock.Unlock()
+
+	instances, err := inspectInstances(sessionID(ctx))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	return outputToProto(eflint.Output{Success: true, Instances: instances}), nil
+}
+
+// observeUnary counts the calls of the unary methods as requests of the
+// kind that they stand for, and gives them a logger with their request ID.
+func observeUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	requestLogger := grpcRequestLogger(ctx)
+	resp, err := handler(context.WithValue(ctx, loggerKey{}, requestLogger), req)
+
+	kind := invalidKind
+	if status.Code(err) != codes.InvalidArgument {
+		switch req := req.(type) {
+		case *eflintpb.Input:
+			kind = req.GetKind()
+		case *eflintpb.PingRequest:
+			kind = "ping"
+		case *eflintpb.HandshakeRequest:
+			kind = "handshake"
+		case *eflintpb.InspectRequest:
+			kind = "inspect"
+		}
+	}
+
+	observeRequest("grpc", kind, start)
+	if err != nil {
+		requestLogger.Info("call failed", "method", info.FullMethod, "error", err)
+	} else {
+		requestLogger.Debug("request handled", "method", info.FullMethod, "kind", kind, "duration", time.Since(start))
+	}
+
+	return resp, err
+}
+
+// sessionID returns the ID of the session that a call is about, from its
+// metadata, or an empty string if it has none.
+func sessionID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(sessionMetadata); len(values) > 0 {
+			return values[0]
+		}
+	}
+
+	return ""
+}
+
+// Session binds the stream to a session, in the same way as websocketHandler
+// does for WebSocket connections.
+func (reasonerServer) Session(stream eflintpb.Reasoner_SessionServer) error {
+	id := sessionID(stream.Context())
+
+	s := openSession(id)
+	if s == nil {
+		return status.Errorf(codes.NotFound, "unknown session %s", id)
+	}
+
+	conn := &grpcConnection{stream: stream, out: newOutbox()}
+	defer conn.close()
+	go conn.out.run()
+
+	sessionsLock.Lock()
+	s.connections[conn] = struct{}{}
+	sessionsLock.Unlock()
+
+	defer func() {
+		sessionsLock.Lock()
+		delete(s.connections, conn)
+		s.lastUsed = time.Now()
+		sessionsLock.Unlock()
+	}()
+
+	if err := conn.send(streamMessage{Kind: "session", Session: s.id}); err != nil {
+		return err
+	}
+
+	// The stream ends when its messages cannot be sent, as it cannot be
+	// closed otherwise
+	received := make(chan error, 1)
+	go func() { received <- conn.receive(s) }()
+
+	select {
+	case err := <-received:
+		conn.out.flush()
+		return err
+	case <-conn.out.done:
+		return status.Error(codes.Unavailable, errSlowConnection.Error())
+	}
+}
+
+// receive runs the inputs of the stream in the session, until the client
+// stops sending.
+func (c *grpcConnection) receive(s *session) error {
+	for {
+		in, err := c.stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		start := time.Now()
+		input := inputFromProto(in)
+		err = s.run(c, input, logger.With("session", s.id, "request", newID()))
+		if err != nil {
+			c.send(streamMessage{Kind: "error", Error: err.Error()})
+		}
+		observeRequest("grpc", requestKind(input, err), start)
+	}
+}
+
+// grpcConnection is a session stream, which is a client of its session like
+// a WebSocket connection.
+type grpcConnection struct {
+	stream eflintpb.Reasoner_SessionServer
+	out    *outbox
+}
+
+func (c *grpcConnection) send(message streamMessage) error {
+	m := &eflintpb.SessionMessage{
+		Kind:    message.Kind,
+		Session: message.Session,
+		Error:   message.Error,
+	}
+
+	if message.Index != nil {
+		m.Index = int32(*message.Index)
+	}
+
+	if message.Result != nil {
+		m.Result = phraseResultToProto(*message.Result)
+	}
+
+	if message.Output != nil {
+		m.Output = outputToProto(*message.Output)
+	}
+
+	return c.out.push(func() error { return c.stream.Send(m) })
+}
+
+func (c *grpcConnection) sendHandshake(handshake eflint.Handshake) error {
+	m := &eflintpb.SessionMessage{Kind: "handshake", Handshake: handshakeToProto(handshake)}
+	return c.out.push(func() error { return c.stream.Send(m) })
+}
+
+// close stops sending messages, which ends the stream.
+func (c *grpcConnection) close() {
+	c.out.close()
+}
+
+// internalError turns the error of a failed interpreter into an INTERNAL
+// status. It must be deferred before recoverInterpreter.
+func internalError(err *error) {
+	var rejected *inputError
+	if errors.As(*err, &rejected) {
+		*err = status.Error(codes.Internal, rejected.Error())
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"unicode"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/logging"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDHeader is the header that a client can name its request with. The
+// ID is logged on every line about the request, and sent back in the same
+// header; requests without one get a random ID.
+const requestIDHeader = "X-Request-ID"
+
+// requestIDMetadata is requestIDHeader for gRPC calls.
+const requestIDMetadata = "x-request-id"
+
+// logger is the logger of the server, which is configured by the flags.
+// Loggers of requests and sessions are derived from it.
+var logger = logging.New(os.Stderr, logging.LevelInfo, logging.FormatLogfmt)
+
+// requestID returns the ID that the client gave to its request, or a new one
+// if it has none or its ID is not fit for logging.
+func requestID(id string) string {
+	if id == "" || len(id) > 128 {
+		return newID()
+	}
+
+	for _, r := range id {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return newID()
+		}
+	}
+
+	return id
+}
+
+// httpRequestLogger returns the logger of an HTTP request, and announces the
+// ID of the request in the response.
+func httpRequestLogger(w http.ResponseWriter, r *http.Request) *logging.Logger {
+	id := requestID(r.Header.Get(requestIDHeader))
+	w.Header().Set(requestIDHeader, id)
+
+	return logger.With("request", id)
+}
+
+// useLogger makes the interpreter log with the logger of a request, and
+// returns a function that restores the logger of the server. The interpreter
+// must be locked until then.
+func useLogger(l *logging.Logger) func() {
+	eflint.SetLogger(l)
+
+	return func() {
+		eflint.SetLogger(logger)
+	}
+}
+
+type loggerKey struct{}
+
+// grpcRequestLogger returns the logger of a gRPC call, with the request ID
+// from its metadata if it has one.
+func grpcRequestLogger(ctx context.Context) *logging.Logger {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadata); len(values) > 0 {
+			id = values[0]
+		}
+	}
+
+	return logger.With("request", requestID(id))
+}
+
+// contextLogger returns the logger that observeUnary added to the context of
+// a gRPC call.
+func contextLogger(ctx context.Context) *logging.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*logging.Logger); ok {
+		return l
+	}
+
+	return logger
+}
+
+// configureLogging sets up the logger of the server and of the interpreter.
+func configureLogging(level string, format string, audit bool) error {
+	parsedLevel, err := logging.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	parsedFormat, err := logging.ParseFormat(format)
+	if err != nil {
+		return err
+	}
+
+	logger = logging.New(os.Stderr, parsedLevel, parsedFormat)
+	eflint.SetLogger(logger)
+	eflint.SetAudit(audit)
+
+	return nil
+}
@@ -1,31 +1,67 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
-	"log"
+	"io"
+	"net"
 	"net/http"
+	"os"
+	"time"
 )
 
 // handler for the root path
 func eFLINTHandler(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	kind := invalidKind
+	requestLogger := httpRequestLogger(w, r)
+	defer func() {
+		observeRequest("http", kind, start)
+		requestLogger.Debug("request handled", "kind", kind, "duration", time.Since(start))
+	}()
+
+	interpreterLock.Lock()
+	defer interpreterLock.Unlock()
+	defer useLogger(requestLogger)()
+
+	// Every request starts from an empty state, so that nothing of earlier
+	// requests is answered, not even to requests without phrases
+	eflint.Reset()
+
 	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		rejectInput(w, []eflint.Error{{Id: "request", Code: codeMethodNotAllowed, Message: "inputs must be sent with POST"}})
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		rejectInput(w, []eflint.Error{{Id: "request", Code: codeInvalidJSON, Message: err.Error()}})
+		return
+	}
+
+	// Check the input against the OpenAPI description first, which points
+	// out every invalid field
+	if errs := validateInput(data); len(errs) > 0 {
+		requestLogger.Info("input rejected", "error", errorsMessage(errs))
+		rejectInput(w, errs)
+		return
+	}
+
 	var input eflint.Input
-	decoder := json.NewDecoder(r.Body)
+	decoder := json.NewDecoder(bytes.NewReader(data))
 	decoder.DisallowUnknownFields()
-	err := decoder.Decode(&input)
+	err = decoder.Decode(&input)
 
 	// Check for parsing errors
 	if err != nil {
-		log.Println(err)
-		output, err := eflint.GenerateJSON(eflint.Output{Success: false})
-
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		w.Write(output)
+		requestLogger.Info("input rejected", "error", err)
+		rejectInput(w, []eflint.Error{{Id: "decode", Code: codeInvalidValue, Message: err.Error()}})
 		return
 	}
 
@@ -33,56 +69,98 @@ func eFLINTHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Check for typechecking errors
 	if err != nil {
-		log.Println(err)
-		output, err := eflint.GenerateJSON(eflint.Output{Success: false})
+		requestLogger.Info("input rejected", "error", err)
+		rejectInput(w, []eflint.Error{typecheckError(err)})
+		return
+	}
 
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
+	kind = input.Kind
+
+	output, err := respond(input, r.URL.Query().Get("session"))
 
-		w.Write(output)
+	var rejected *inputError
+	if errors.As(err, &rejected) {
+		requestLogger.Info("input failed", "error", err)
+		rejectInput(w, rejected.errs)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	//pp.Println(input)
+	w.Write(output)
+}
+
+// respond runs an input that has been typechecked, and returns the response
+// to it. Inputs that cannot be run, including those on which the interpreter
+// panics, fail with an inputError.
+func respond(input eflint.Input, session string) (response []byte, err error) {
+	defer recoverInterpreter(&err)
+
+	output := eflint.Output{Success: true}
 
-	// TODO: Do something with the input
 	switch input.Kind {
 	case "phrases":
 		eflint.InterpretPhrases(input.Phrases)
+	case "duties", "explore", "graph":
+		eflint.InterpretPhrases(input.Phrases)
+		if output, err = kindOutput(input); err != nil {
+			return nil, err
+		}
 	case "handshake":
-		handshake, err := eflint.GenerateHandshake()
+		return eflint.GenerateHandshake(input.Version)
+	case "inspect":
+		instances, err := inspectInstances(session)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+			return nil, &inputError{[]eflint.Error{{Id: "session", Code: codeUnknownSession, Message: err.Error()}}}
 		}
-		w.Write(handshake)
-		return
+		output.Instances = instances
 	case "ping":
 	default:
-		// TODO: This should have been handled by a typecheck function
-		http.Error(w, "Unknown kind", http.StatusBadRequest)
-		return
+		return nil, &inputError{[]eflint.Error{typecheckError(eflint.ErrUnknownKind)}}
 	}
 
-	// Write the response
-	output, err := eflint.GenerateJSON(eflint.Output{Success: true})
-	//output, err := eflint.GenerateJSON(eflint.Output{Success: true, Phrases: input.Phrases})
+	return eflint.GenerateVersionedJSON(output, input.Version, input.Extensions)
+}
 
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+func main() {
+	grpcAddress := flag.String("grpc", ":8081", "address to serve the gRPC API on, or empty to disable it")
+	logLevel := flag.String("log-level", "info", "lowest level of the lines that are logged: debug, info, warn or error")
+	logFormat := flag.String("log-format", "logfmt", "format of the log lines: logfmt or json")
+	audit := flag.Bool("audit", false, "log every phrase that is interpreted with its result")
+	hosts := flag.String("webhook-hosts", "", "comma-separated hosts that webhooks can be posted to even though they are loopback, link-local or private addresses")
+	flag.Parse()
+
+	webhookHosts = splitList(*hosts)
+
+	if err := configureLogging(*logLevel, *logFormat, *audit); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
 
-	w.Write(output)
-	//log.Println("Handled request")
+	http.HandleFunc("/", eFLINTHandler)
+	http.HandleFunc("/ws", websocketHandler)
+	http.HandleFunc("/sessions/", sessionsHandler)
+	http.HandleFunc(openAPIPath, openAPIHandler)
+	http.Handle(metricsPath, metricsHandler)
+	go expireSessions()
+
+	if *grpcAddress != "" {
+		listener, err := net.Listen("tcp", *grpcAddress)
+		if err != nil {
+			logger.Error("cannot serve gRPC", "error", err)
+			os.Exit(1)
+		}
 
-	return
-}
+		logger.Info("serving gRPC", "address", listener.Addr())
+		go func() {
+			logger.Error("gRPC server stopped", "error", newGRPCServer().Serve(listener))
+			os.Exit(1)
+		}()
+	}
 
-func main() {
-	http.HandleFunc("/", eFLINTHandler)
-	log.Println("Starting at http://localhost:8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	logger.Info("starting", "url", "http://localhost:8080")
+	logger.Error("server stopped", "error", http.ListenAndServe(":8080", nil))
+	os.Exit(1)
 }
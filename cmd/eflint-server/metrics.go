@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsPath = "/metrics"
+
+// invalidKind is the kind that rejected requests are counted under, as their
+// kind may be anything.
+const invalidKind = "invalid"
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "eflint_requests_total",
+		Help: "Requests handled, by transport and kind.",
+	}, []string{"transport", "kind"})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "eflint_request_duration_seconds",
+		Help:    "Time taken to handle a request, by transport and kind.",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 4, 10),
+	}, []string{"transport", "kind"})
+	phrasesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "eflint_phrases_total",
+		Help: "Phrases interpreted, by kind.",
+	}, []string{"kind"})
+	derivationIterations = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "eflint_derivation_iterations",
+		Help:    "Iterations taken to derive the facts, by strategy.",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+	}, []string{"strategy"})
+	derivationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "eflint_derivation_duration_seconds",
+		Help:    "Time taken to derive the facts, by strategy.",
+		Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10),
+	}, []string{"strategy"})
+	violationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "eflint_violations_total",
+		Help: "Violations raised, by kind.",
+	}, []string{"kind"})
+	sessionInstances = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "eflint_session_instances",
+		Help: "Instances that hold in a session, by session.",
+	}, []string{"session"})
+)
+
+// metricsRegistry holds the metrics of the server, and those of the Go
+// runtime and the process, such as the number of goroutines.
+var metricsRegistry = newMetricsRegistry()
+
+func newMetricsRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requestsTotal,
+		requestDuration,
+		phrasesTotal,
+		derivationIterations,
+		derivationDuration,
+		violationsTotal,
+		sessionInstances,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "eflint_sessions_active",
+			Help: "Sessions that have not expired.",
+		}, func() float64 {
+			sessionsLock.Lock()
+			defer sessionsLock.Unlock()
+
+			return float64(len(sessions))
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "eflint_session_connections",
+			Help: "WebSocket and gRPC streams that are connected to a session.",
+		}, func() float64 {
+			sessionsLock.Lock()
+			defer sessionsLock.Unlock()
+
+			count := 0
+			for _, s := range sessions {
+				count += len(s.connections)
+			}
+
+			return float64(count)
+		}),
+	)
+
+	return registry
+}
+
+var metricsHandler = promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+
+// metricsObserver counts the work of the interpreter.
+type metricsObserver struct{}
+
+func (metricsObserver) PhraseInterpreted(kind string) {
+	phrasesTotal.WithLabelValues(kind).Inc()
+}
+
+func (metricsObserver) Derived(strategy string, iterations int, duration time.Duration) {
+	derivationIterations.WithLabelValues(strategy).Observe(float64(iterations))
+	derivationDuration.WithLabelValues(strategy).Observe(duration.Seconds())
+}
+
+func (metricsObserver) Violated(kind string) {
+	violationsTotal.WithLabelValues(kind).Inc()
+}
+
+func init() {
+	eflint.SetObserver(metricsObserver{})
+}
+
+// observeRequest counts a request that was handled since start.
+func observeRequest(transport string, kind string, start time.Time) {
+	requestsTotal.WithLabelValues(transport, kind).Inc()
+	requestDuration.WithLabelValues(transport, kind).Observe(time.Since(start).Seconds())
+}
+
+// requestKind is the kind that an input that was run with the given error is
+// counted under.
+func requestKind(input eflint.Input, err error) string {
+	var rejected *inputError
+	if errors.As(err, &rejected) {
+		return invalidKind
+	}
+
+	return input.Kind
+}
@@ -0,0 +1,430 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/openapi"
+)
+
+// openAPIPath is the path that the OpenAPI description of the server is
+// served at.
+const openAPIPath = "/openapi.json"
+
+// apiDocument describes the HTTP API of the server. Requests are validated
+// against it before they are decoded.
+var apiDocument = newAPIDocument()
+
+// The inputs of the different kinds, which share the Input type in package
+// eflint.
+type (
+	phrasesInput struct {
+		Version    string          `json:"version"`
+		Kind       string          `json:"kind"`
+		Phrases    []eflint.Phrase `json:"phrases"`
+		Updates    bool            `json:"updates,omitempty"`
+		Extensions []string        `json:"extensions,omitempty"`
+	}
+
+	exploreInput struct {
+		Version    string             `json:"version"`
+		Kind       string             `json:"kind"`
+		Phrases    []eflint.Phrase    `json:"phrases"`
+		Updates    bool               `json:"updates,omitempty"`
+		Depth      int                `json:"depth,omitempty"`
+		Goal       *eflint.Expression `json:"goal,omitempty"`
+		Extensions []string           `json:"extensions,omitempty"`
+	}
+
+	requestInput struct {
+		Version    string   `json:"version"`
+		Kind       string   `json:"kind"`
+		Extensions []string `json:"extensions,omitempty"`
+	}
+)
+
+func newAPIDocument() *openapi.Document {
+	g := openapi.NewGenerator()
+
+	named := map[string]interface{}{
+		"Expression":   eflint.Expression{},
+		"Phrase":       eflint.Phrase{},
+		"PhraseResult": eflint.PhraseResult{},
+		"Error":        eflint.Error{},
+		"Trigger":      eflint.Trigger{},
+		"Violation":    eflint.Violation{},
+		"DutyGroup":    eflint.DutyGroup{},
+		"DutyInstance": eflint.DutyInstance{},
+		"Trace":        eflint.Trace{},
+		"Graph":        eflint.Graph{},
+		"GraphNode":    eflint.GraphNode{},
+		"GraphEdge":    eflint.GraphEdge{},
+		"Output":       eflint.Output{},
+	}
+	for name, v := range named {
+		g.Name(v, name)
+	}
+
+	schemas := map[string]*openapi.Schema{
+		"Input": discriminated("kind", map[string]string{
+			"phrases":   "PhrasesInput",
+			"duties":    "PhrasesInput",
+			"graph":     "PhrasesInput",
+			"explore":   "ExploreInput",
+			"handshake": "RequestInput",
+			"ping":      "RequestInput",
+			"inspect":   "RequestInput",
+		}),
+		"PhrasesInput": withKinds(g.Object(phrasesInput{}), "phrases", "duties", "graph"),
+		"ExploreInput": withKinds(g.Object(exploreInput{}), "explore"),
+		"RequestInput": withKinds(g.Object(requestInput{}), "handshake", "ping", "inspect"),
+
+		"Phrase": discriminated("kind", map[string]string{
+			"bquery":      "Query",
+			"iquery":      "Query",
+			"create":      "Statement",
+			"terminate":   "Statement",
+			"obfuscate":   "Statement",
+			"trigger":     "Statement",
+			"afact":       "AtomicFact",
+			"cfact":       "CompositeFact",
+			"placeholder": "Placeholder",
+			"predicate":   "Predicate",
+			"event":       "Event",
+			"act":         "Act",
+			"duty":        "Duty",
+			"extend":      "Extend",
+		}),
+		"Query":         phrase(g.Object(eflint.Query{}), "bquery", "iquery"),
+		"Statement":     phrase(g.Object(eflint.Statement{}), "create", "terminate", "obfuscate", "trigger"),
+		"AtomicFact":    phrase(required(g.Object(eflint.AtomicFact{}), "name"), "afact"),
+		"CompositeFact": phrase(g.Object(eflint.CompositeFact{}), "cfact"),
+		"Placeholder":   phrase(g.Object(eflint.Placeholder{}), "placeholder"),
+		"Predicate":     phrase(g.Object(eflint.Predicate{}), "predicate"),
+		"Event":         phrase(g.Object(eflint.Event{}), "event"),
+		"Act":           phrase(g.Object(eflint.Act{}), "act"),
+		"Duty":          phrase(required(g.Object(eflint.Duty{}), "name", "holder", "claimant"), "duty"),
+		"Extend":        phrase(g.Object(eflint.Extend{}), "extend"),
+
+		"Expression": {
+			Description: "A primitive value, a variable reference, or one of the expression objects.",
+			OneOf: []*openapi.Schema{
+				{Type: "string"},
+				{Type: "integer"},
+				{Type: "boolean"},
+				openapi.Ref("VariableReference"),
+				openapi.Ref("ConstructorApplication"),
+				openapi.Ref("Operator"),
+				openapi.Ref("Iterator"),
+				openapi.Ref("Projection"),
+			},
+		},
+		"VariableReference": {
+			Description: "A reference to the variable with the given name.",
+			Type:        "array",
+			Items:       &openapi.Schema{Type: "string"},
+			MinItems:    intPointer(1),
+			MaxItems:    intPointer(1),
+		},
+		"ConstructorApplication": required(g.Object(eflint.ConstructorApplication{}), "identifier"),
+		"Operator":               g.Object(eflint.Operator{}),
+		"Iterator":               g.Object(eflint.Iterator{}),
+		"Projection":             g.Object(eflint.Projection{}),
+
+		"Output": g.Object(eflint.Output{}),
+		"PhraseResult": {
+			Description: "The result of a boolean query, an instance query, or any other phrase.",
+			OneOf: []*openapi.Schema{
+				openapi.Ref("BQueryResult"),
+				openapi.Ref("IQueryResult"),
+				openapi.Ref("StateChanges"),
+			},
+		},
+		"BQueryResult":  g.Object(eflint.BQueryResult{}),
+		"IQueryResult":  g.Object(eflint.IQueryResult{}),
+		"StateChanges":  g.Object(eflint.StateChanges{}),
+		"Error":         g.Object(eflint.Error{}),
+		"Trigger":       g.Object(eflint.Trigger{}),
+		"Violation":     g.Object(eflint.Violation{}),
+		"DutyGroup":     g.Object(eflint.DutyGroup{}),
+		"DutyInstance":  g.Object(eflint.DutyInstance{}),
+		"Trace":         g.Object(eflint.Trace{}),
+		"Graph":         g.Object(eflint.Graph{}),
+		"GraphNode":     g.Object(eflint.GraphNode{}),
+		"GraphEdge":     g.Object(eflint.GraphEdge{}),
+		"Handshake":     g.Object(eflint.Handshake{}),
+		"StreamMessage": g.Object(streamMessage{}),
+		"Subscription":  required(g.Object(subscription{}), "webhook"),
+		"SessionEvent":  g.Object(event{}),
+	}
+
+	schemas["ExploreInput"].Properties["depth"].Minimum = floatPointer(0)
+	for _, input := range []string{"PhrasesInput", "ExploreInput", "RequestInput"} {
+		schemas[input].Properties["extensions"].Items.Enum = eflint.SupportedExtensions()
+	}
+	schemas["Subscription"].Properties["changes"].Items.Enum = changeKinds
+	schemas["Subscription"].Properties["violations"].Items.Enum = violationKinds
+
+	codes := make([]string, 0, len(codeStatus))
+	for code := range codeStatus {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	schemas["Error"].Properties["code"].Enum = codes
+
+	sessionID := openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}
+	notFound := &openapi.Response{Description: "The session or subscription does not exist."}
+
+	return &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title: "eFLINT server",
+			Description: "Runs eFLINT phrases in the JSON specification. Requests name the version of the " +
+				"specification they use; the supported versions are " + strings.Join(eflint.SupportedVersions(), ", ") + ".",
+			Version: eflint.ReasonerVersion,
+		},
+		Paths: map[string]*openapi.PathItem{
+			"/": {
+				Post: &openapi.Operation{
+					OperationID: "request",
+					Summary:     "Run an input of any kind, starting from an empty state",
+					Parameters: []openapi.Parameter{{
+						Name:        "session",
+						In:          "query",
+						Description: "The ID of the session that an inspect request inspects.",
+						Schema:      &openapi.Schema{Type: "string"},
+					}},
+					RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSON(openapi.Ref("Input"))},
+					Responses: map[string]*openapi.Response{
+						"200": {
+							Description: "The handshake for handshake requests, and the output otherwise.",
+							Content: openapi.JSON(&openapi.Schema{OneOf: []*openapi.Schema{
+								openapi.Ref("Output"),
+								openapi.Ref("Handshake"),
+							}}),
+						},
+						"400": rejected("The input is not well-formed."),
+						"404": rejected("The session to inspect does not exist."),
+						"405": rejected("The input was not sent with POST."),
+						"422": rejected("The input is well-formed, but cannot be run, for example because its version is not supported."),
+					},
+				},
+			},
+			"/ws": {
+				Get: &openapi.Operation{
+					OperationID: "session",
+					Summary:     "Open a WebSocket session",
+					Description: "Messages from the client are inputs, and messages from the server are stream " +
+						"messages, apart from the handshake, which is sent as is.",
+					Parameters: []openapi.Parameter{{
+						Name:        "session",
+						In:          "query",
+						Description: "The session to join, or a new session if it is left out.",
+						Schema:      &openapi.Schema{Type: "string"},
+					}},
+					Responses: map[string]*openapi.Response{
+						"101": {Description: "The connection is upgraded to WebSocket.", Content: openapi.JSON(openapi.Ref("StreamMessage"))},
+						"404": notFound,
+					},
+				},
+			},
+			"/sessions/{id}/subscriptions": {
+				Parameters: []openapi.Parameter{sessionID},
+				Get: &openapi.Operation{
+					OperationID: "listSubscriptions",
+					Summary:     "List the webhook subscriptions of a session",
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "The subscriptions.", Content: openapi.JSON(&openapi.Schema{Type: "array", Items: openapi.Ref("Subscription")})},
+						"404": notFound,
+					},
+				},
+				Post: &openapi.Operation{
+					OperationID: "subscribe",
+					Summary:     "Post the changes and violations of a session to a webhook",
+					RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSON(openapi.Ref("Subscription"))},
+					Responses: map[string]*openapi.Response{
+						"201": {Description: "The subscription, with its ID.", Content: openapi.JSON(openapi.Ref("Subscription"))},
+						"400": rejected("The subscription is invalid."),
+						"404": notFound,
+					},
+				},
+			},
+			"/sessions/{id}/subscriptions/{subscription}": {
+				Parameters: []openapi.Parameter{
+					sessionID,
+					{Name: "subscription", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}},
+				},
+				Delete: &openapi.Operation{
+					OperationID: "unsubscribe",
+					Summary:     "Remove a subscription",
+					Responses: map[string]*openapi.Response{
+						"204": {Description: "The subscription was removed."},
+						"404": notFound,
+					},
+				},
+			},
+			"/sessions/{id}/events": {
+				Parameters: []openapi.Parameter{sessionID},
+				Get: &openapi.Operation{
+					OperationID: "events",
+					Summary:     "Stream the changes and violations of a session as Server-Sent Events",
+					Parameters: []openapi.Parameter{
+						listParameter("facts", "The facts to receive changes and violations of."),
+						listParameter("changes", "The kinds of changes to receive: "+strings.Join(changeKinds, ", ")+"."),
+						listParameter("violations", "The kinds of violations to receive: "+strings.Join(violationKinds, ", ")+"."),
+					},
+					Responses: map[string]*openapi.Response{
+						"200": {
+							Description: "An event for every change or violation, named after its kind.",
+							Content:     map[string]openapi.MediaType{"text/event-stream": {Schema: openapi.Ref("SessionEvent")}},
+						},
+						"404": notFound,
+					},
+				},
+			},
+			openAPIPath: {
+				Get: &openapi.Operation{
+					OperationID: "openapi",
+					Summary:     "This description",
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "The OpenAPI description of the server.", Content: openapi.JSON(&openapi.Schema{Type: "object"})},
+					},
+				},
+			},
+			metricsPath: {
+				Get: &openapi.Operation{
+					OperationID: "metrics",
+					Summary:     "Metrics of the server in the Prometheus text format",
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "The metrics of the server.", Content: map[string]openapi.MediaType{"text/plain": {Schema: &openapi.Schema{Type: "string"}}}},
+					},
+				},
+			},
+		},
+		Components: openapi.Components{Schemas: schemas},
+	}
+}
+
+// discriminated is a schema that is picked by the value of a property.
+func discriminated(property string, mapping map[string]string) *openapi.Schema {
+	schema := &openapi.Schema{Discriminator: &openapi.Discriminator{PropertyName: property, Mapping: make(map[string]string)}}
+
+	seen := make(map[string]bool)
+	for value, name := range mapping {
+		ref := openapi.Ref(name)
+		schema.Discriminator.Mapping[value] = ref.Ref
+		if !seen[name] {
+			seen[name] = true
+			schema.OneOf = append(schema.OneOf, ref)
+		}
+	}
+
+	// Keep the document the same between runs
+	sort.Slice(schema.OneOf, func(i, j int) bool { return schema.OneOf[i].Ref < schema.OneOf[j].Ref })
+
+	return schema
+}
+
+// rejected is the response to a request that is rejected, which has an error
+// with a code for every reason.
+func rejected(description string) *openapi.Response {
+	return &openapi.Response{Description: description, Content: openapi.JSON(openapi.Ref("Output"))}
+}
+
+// withKinds restricts the kind of a schema to the given kinds.
+func withKinds(schema *openapi.Schema, kinds ...string) *openapi.Schema {
+	schema.Properties["kind"] = &openapi.Schema{Type: "string", Enum: kinds}
+	return schema
+}
+
+// phrase adds the fields that all phrases have to the schema of a kind of
+// phrase.
+func phrase(schema *openapi.Schema, kinds ...string) *openapi.Schema {
+	schema.Properties["stateless"] = &openapi.Schema{Type: "boolean"}
+	schema.Properties["updates"] = &openapi.Schema{Type: "boolean"}
+	schema.Required = append([]string{"kind"}, schema.Required...)
+	return withKinds(schema, kinds...)
+}
+
+// required replaces the required fields of a schema, as not all fields that
+// the reasoner writes out are needed in requests.
+func required(schema *openapi.Schema, fields ...string) *openapi.Schema {
+	schema.Required = fields
+	return schema
+}
+
+func listParameter(name string, description string) openapi.Parameter {
+	return openapi.Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description + " A comma-separated list.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+}
+
+func intPointer(i int) *int {
+	return &i
+}
+
+func floatPointer(f float64) *float64 {
+	return &f
+}
+
+func openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, apiDocument)
+}
+
+// validateInput validates an input against the description of the server,
+// after it has been rewritten into the format of the interpreter. It returns
+// an error for every field that is invalid.
+func validateInput(data []byte) []eflint.Error {
+	value, err := decodeValue(data)
+	if err != nil {
+		return []eflint.Error{{Id: "validation", Code: codeInvalidJSON, Message: "invalid JSON: " + err.Error()}}
+	}
+
+	if _, ok := value.(map[string]interface{}); ok {
+		upgraded, err := eflint.UpgradeInput(data)
+		if err != nil {
+			return []eflint.Error{{Id: "validation", Code: codeInvalidJSON, Message: err.Error()}}
+		}
+
+		if value, err = decodeValue(upgraded); err != nil {
+			return []eflint.Error{{Id: "validation", Code: codeInvalidJSON, Message: err.Error()}}
+		}
+	}
+
+	return validationErrors(apiDocument.Validate(openapi.Ref("Input"), value))
+}
+
+// validateSchema validates a request body against a schema of the
+// description of the server.
+func validateSchema(data []byte, name string) []eflint.Error {
+	value, err := decodeValue(data)
+	if err != nil {
+		return []eflint.Error{{Id: "validation", Code: codeInvalidJSON, Message: "invalid JSON: " + err.Error()}}
+	}
+
+	return validationErrors(apiDocument.Validate(openapi.Ref(name), value))
+}
+
+func decodeValue(data []byte) (interface{}, error) {
+	var value interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
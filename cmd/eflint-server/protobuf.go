@@ -0,0 +1,339 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflintpb"
+)
+
+// The functions below convert between the protocol buffer messages of the
+// gRPC API and the structs of the interpreter.
+
+func inputFromProto(in *eflintpb.Input) eflint.Input {
+	input := eflint.Input{
+		Version: in.GetVersion(),
+		Kind:    in.GetKind(),
+		Phrases: make([]eflint.Phrase, 0, len(in.GetPhrases())),
+		Updates: in.GetUpdates(),
+		Depth:   int(in.GetDepth()),
+	}
+
+	for _, phrase := range in.GetPhrases() {
+		input.Phrases = append(input.Phrases, phraseFromProto(phrase))
+	}
+
+	if in.GetGoal() != nil {
+		goal := expressionFromProto(in.GetGoal())
+		input.Goal = &goal
+	}
+
+	return input
+}
+
+func inputToProto(input eflint.Input) *eflintpb.Input {
+	return &eflintpb.Input{
+		Version: input.Version,
+		Kind:    input.Kind,
+		Phrases: phrasesToProto(input.Phrases),
+		Updates: input.Updates,
+		Depth:   int32(input.Depth),
+		Goal:    optionalExpressionToProto(input.Goal),
+	}
+}
+
+func phraseFromProto(p *eflintpb.Phrase) eflint.Phrase {
+	phrase := eflint.Phrase{
+		Kind:          p.GetKind(),
+		Stateless:     p.GetStateless(),
+		Updates:       p.GetUpdates(),
+		Expression:    optionalExpressionFromProto(p.GetExpression()),
+		Operand:       optionalExpressionFromProto(p.GetOperand()),
+		Name:          p.GetName(),
+		Type:          p.GetType(),
+		Range:         expressionsFromProto(p.GetRange()),
+		WhenTrue:      p.GetWhenTrue(),
+		DerivedFrom:   expressionsFromProto(p.GetDerivedFrom()),
+		HoldsWhen:     expressionsFromProto(p.GetHoldsWhen()),
+		ConditionedBy: expressionsFromProto(p.GetConditionedBy()),
+		IdentifiedBy:  p.GetIdentifiedBy(),
+		For:           p.GetFor(),
+		IsInvariant:   p.GetIsInvariant(),
+		RelatedTo:     p.GetRelatedTo(),
+		SyncsWith:     expressionsFromProto(p.GetSyncsWith()),
+		Creates:       expressionsFromProto(p.GetCreates()),
+		Terminates:    expressionsFromProto(p.GetTerminates()),
+		Obfuscates:    expressionsFromProto(p.GetObfuscates()),
+		Actor:         p.GetActor(),
+		Holder:        p.GetHolder(),
+		Claimant:      p.GetClaimant(),
+		ViolatedWhen:  expressionsFromProto(p.GetViolatedWhen()),
+		ParentKind:    p.GetParentKind(),
+	}
+
+	// Placeholders have several names, like in the JSON specification
+	if phrase.Kind == "placeholder" {
+		phrase.Name = p.GetNames()
+	}
+
+	return phrase
+}
+
+func phraseToProto(phrase eflint.Phrase) *eflintpb.Phrase {
+	p := &eflintpb.Phrase{
+		Kind:          phrase.Kind,
+		Stateless:     phrase.Stateless,
+		Updates:       phrase.Updates,
+		Expression:    optionalExpressionToProto(phrase.Expression),
+		Operand:       optionalExpressionToProto(phrase.Operand),
+		Type:          phrase.Type,
+		Range:         expressionsToProto(phrase.Range),
+		WhenTrue:      phrase.WhenTrue,
+		DerivedFrom:   expressionsToProto(phrase.DerivedFrom),
+		HoldsWhen:     expressionsToProto(phrase.HoldsWhen),
+		ConditionedBy: expressionsToProto(phrase.ConditionedBy),
+		IdentifiedBy:  phrase.IdentifiedBy,
+		For:           phrase.For,
+		IsInvariant:   phrase.IsInvariant,
+		RelatedTo:     phrase.RelatedTo,
+		SyncsWith:     expressionsToProto(phrase.SyncsWith),
+		Creates:       expressionsToProto(phrase.Creates),
+		Terminates:    expressionsToProto(phrase.Terminates),
+		Obfuscates:    expressionsToProto(phrase.Obfuscates),
+		Actor:         phrase.Actor,
+		Holder:        phrase.Holder,
+		Claimant:      phrase.Claimant,
+		ViolatedWhen:  expressionsToProto(phrase.ViolatedWhen),
+		ParentKind:    phrase.ParentKind,
+	}
+
+	switch name := phrase.Name.(type) {
+	case string:
+		p.Name = name
+	case []string:
+		p.Names = name
+	}
+
+	return p
+}
+
+func phrasesToProto(phrases []eflint.Phrase) []*eflintpb.Phrase {
+	result := make([]*eflintpb.Phrase, 0, len(phrases))
+	for _, phrase := range phrases {
+		result = append(result, phraseToProto(phrase))
+	}
+
+	return result
+}
+
+func expressionFromProto(e *eflintpb.Expression) eflint.Expression {
+	expression := eflint.Expression{
+		Operator:   e.GetOperator(),
+		Identifier: e.GetIdentifier(),
+		Operands:   expressionsFromProto(e.GetOperands()),
+		Iterator:   e.GetIterator(),
+		Binds:      e.GetBinds(),
+		Expression: optionalExpressionFromProto(e.GetExpression()),
+		Operand:    optionalExpressionFromProto(e.GetOperand()),
+		Parameter:  e.GetParameter(),
+	}
+
+	switch value := e.GetValue().GetValue().(type) {
+	case *eflintpb.Value_String_:
+		expression.Value = value.String_
+	case *eflintpb.Value_Integer:
+		expression.Value = value.Integer
+	case *eflintpb.Value_Boolean:
+		expression.Value = value.Boolean
+	case *eflintpb.Value_Reference:
+		expression.Value = []string{value.Reference}
+	}
+
+	return expression
+}
+
+func optionalExpressionFromProto(e *eflintpb.Expression) *eflint.Expression {
+	if e == nil {
+		return nil
+	}
+
+	expression := expressionFromProto(e)
+	return &expression
+}
+
+func expressionsFromProto(expressions []*eflintpb.Expression) []eflint.Expression {
+	if len(expressions) == 0 {
+		return nil
+	}
+
+	result := make([]eflint.Expression, 0, len(expressions))
+	for _, expression := range expressions {
+		result = append(result, expressionFromProto(expression))
+	}
+
+	return result
+}
+
+func expressionToProto(expression eflint.Expression) *eflintpb.Expression {
+	e := &eflintpb.Expression{
+		Operator:   expression.Operator,
+		Identifier: expression.Identifier,
+		Operands:   expressionsToProto(expression.Operands),
+		Iterator:   expression.Iterator,
+		Binds:      expression.Binds,
+		Expression: optionalExpressionToProto(expression.Expression),
+		Operand:    optionalExpressionToProto(expression.Operand),
+		Parameter:  expression.Parameter,
+	}
+
+	switch value := expression.Value.(type) {
+	case nil:
+	case string:
+		e.Value = &eflintpb.Value{Value: &eflintpb.Value_String_{String_: value}}
+	case int64:
+		e.Value = &eflintpb.Value{Value: &eflintpb.Value_Integer{Integer: value}}
+	case int:
+		e.Value = &eflintpb.Value{Value: &eflintpb.Value_Integer{Integer: int64(value)}}
+	case bool:
+		e.Value = &eflintpb.Value{Value: &eflintpb.Value_Boolean{Boolean: value}}
+	case []string:
+		if len(value) == 1 {
+			e.Value = &eflintpb.Value{Value: &eflintpb.Value_Reference{Reference: value[0]}}
+		}
+	default:
+		e.Value = &eflintpb.Value{Value: &eflintpb.Value_String_{String_: fmt.Sprint(value)}}
+	}
+
+	return e
+}
+
+func optionalExpressionToProto(expression *eflint.Expression) *eflintpb.Expression {
+	if expression == nil {
+		return nil
+	}
+
+	return expressionToProto(*expression)
+}
+
+func expressionsToProto(expressions []eflint.Expression) []*eflintpb.Expression {
+	if len(expressions) == 0 {
+		return nil
+	}
+
+	result := make([]*eflintpb.Expression, 0, len(expressions))
+	for _, expression := range expressions {
+		result = append(result, expressionToProto(expression))
+	}
+
+	return result
+}
+
+func outputToProto(output eflint.Output) *eflintpb.Output {
+	o := &eflintpb.Output{
+		Success:   output.Success,
+		Errors:    errorsToProto(output.Errors),
+		Dot:       output.Dot,
+		Instances: expressionsToProto(output.Instances),
+	}
+
+	for _, result := range output.Results {
+		o.Results = append(o.Results, phraseResultToProto(result))
+	}
+
+	for _, group := range output.Duties {
+		g := &eflintpb.DutyGroup{
+			Holder:   expressionToProto(group.Holder),
+			Claimant: expressionToProto(group.Claimant),
+		}
+
+		for _, duty := range group.Duties {
+			g.Duties = append(g.Duties, &eflintpb.DutyInstance{Duty: expressionToProto(duty.Duty), Violated: duty.Violated})
+		}
+
+		o.Duties = append(o.Duties, g)
+	}
+
+	for _, trace := range output.Traces {
+		o.Traces = append(o.Traces, &eflintpb.Trace{
+			Kind:       trace.Kind,
+			Phrases:    phrasesToProto(trace.Phrases),
+			Violations: violationsToProto(trace.Violations),
+		})
+	}
+
+	if output.Graph != nil {
+		o.Graph = &eflintpb.Graph{}
+
+		for _, node := range output.Graph.Nodes {
+			o.Graph.Nodes = append(o.Graph.Nodes, &eflintpb.GraphNode{Name: node.Name, Kind: node.Kind})
+		}
+
+		for _, edge := range output.Graph.Edges {
+			o.Graph.Edges = append(o.Graph.Edges, &eflintpb.GraphEdge{From: edge.From, To: edge.To, Kind: edge.Kind})
+		}
+	}
+
+	return o
+}
+
+func phraseResultToProto(result eflint.PhraseResult) *eflintpb.PhraseResult {
+	r := &eflintpb.PhraseResult{
+		Success: result.Success,
+		Errors:  errorsToProto(result.Errors),
+	}
+
+	switch {
+	case result.IsBquery:
+		r.Kind = eflintpb.PhraseResult_BQUERY
+		r.Result = result.Result
+	case result.IsIquery:
+		r.Kind = eflintpb.PhraseResult_IQUERY
+		r.Instances = expressionsToProto(result.Results)
+	default:
+		r.Kind = eflintpb.PhraseResult_STATE_CHANGES
+		r.Changes = phrasesToProto(result.Changes)
+		r.Violated = result.Violated
+		r.Violations = violationsToProto(result.Violations)
+
+		for _, trigger := range result.Triggers {
+			r.Triggers = append(r.Triggers, &eflintpb.Trigger{Identifier: trigger.Identifier, Kind: trigger.Kind, Parent: trigger.Parent})
+		}
+	}
+
+	return r
+}
+
+func violationsToProto(violations []eflint.Violation) []*eflintpb.Violation {
+	result := make([]*eflintpb.Violation, 0, len(violations))
+	for _, violation := range violations {
+		result = append(result, &eflintpb.Violation{
+			Kind:       violation.Kind,
+			Identifier: violation.Identifier,
+			Operands:   expressionsToProto(violation.Operands),
+		})
+	}
+
+	return result
+}
+
+func errorsToProto(errors []eflint.Error) []*eflintpb.Error {
+	result := make([]*eflintpb.Error, 0, len(errors))
+	for _, err := range errors {
+		result = append(result, &eflintpb.Error{Id: err.Id, Message: err.Message})
+	}
+
+	return result
+}
+
+func handshakeToProto(handshake eflint.Handshake) *eflintpb.Handshake {
+	return &eflintpb.Handshake{
+		Success:           handshake.Success,
+		SupportedVersions: handshake.SupportedVersions,
+		Reasoner:          handshake.Reasoner,
+		ReasonerVersion:   handshake.ReasonerVersion,
+		SharesUpdates:     handshake.SharesUpdates,
+		SharesTriggers:    handshake.SharesTriggers,
+		SharesViolations:  handshake.SharesViolations,
+		Version:           handshake.Version,
+	}
+}
@@ -0,0 +1,542 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/logging"
+)
+
+// webhookAttempts is the number of times an event is posted to a webhook
+// before it is dropped, and webhookBackoff the time before the first retry,
+// which doubles for every next one.
+var (
+	webhookAttempts = 5
+	webhookBackoff  = time.Second
+)
+
+// webhookHosts are the hosts that webhooks can be posted to even though they
+// are loopback, link-local or private addresses, as set with -webhook-hosts.
+// Other webhooks must be public, so that clients cannot make the server post
+// to internal services.
+var webhookHosts []string
+
+// subscriptionBuffer is the number of events that are queued for a
+// subscription. Events for subscribers that cannot keep up are dropped, so
+// that they never hold up the interpreter.
+const subscriptionBuffer = 256
+
+var (
+	changeKinds    = []string{"create", "terminate", "obfuscate", "enabled"}
+	violationKinds = []string{"act", "duty", "invariant"}
+)
+
+// subscription selects the events of a session that a client wants to
+// receive. Empty filters match everything, but a subscription that only
+// lists changes does not receive violations, and the other way around.
+type subscription struct {
+	ID         string   `json:"id"`
+	Facts      []string `json:"facts,omitempty"`
+	Changes    []string `json:"changes,omitempty"`
+	Violations []string `json:"violations,omitempty"`
+	// Webhook is the URL that events are posted to. Subscriptions without
+	// one are streamed to an events request.
+	Webhook string `json:"webhook,omitempty"`
+
+	events    chan event
+	closeOnce sync.Once
+	logger    *logging.Logger
+}
+
+// event is a change or violation in a session. Kind is the kind of change,
+// enabled for an act that becomes enabled, or violation.
+type event struct {
+	Session   string            `json:"session"`
+	Kind      string            `json:"kind"`
+	Violation string            `json:"violation,omitempty"`
+	Fact      string            `json:"fact"`
+	Instance  eflint.Expression `json:"instance"`
+}
+
+func (sub *subscription) validate() error {
+	for _, change := range sub.Changes {
+		if !contains(changeKinds, change) {
+			return fmt.Errorf("unknown change %s, expected one of %s", change, strings.Join(changeKinds, ", "))
+		}
+	}
+
+	for _, violation := range sub.Violations {
+		if !contains(violationKinds, violation) {
+			return fmt.Errorf("unknown violation %s, expected one of %s", violation, strings.Join(violationKinds, ", "))
+		}
+	}
+
+	if sub.Webhook != "" {
+		u, err := url.Parse(sub.Webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook %s, expected an http or https URL", sub.Webhook)
+		}
+
+		if err := checkWebhookHost(u.Hostname()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkWebhookHost returns an error if a webhook host resolves to an address
+// that is not public and is not allowed with -webhook-hosts.
+func checkWebhookHost(host string) error {
+	if allowedWebhookHost(host) {
+		return nil
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve webhook host %s: %v", host, err)
+	}
+
+	for _, ip := range ips {
+		if err := checkWebhookAddress(host, ip); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkWebhookAddress returns an error if a webhook host cannot be reached at
+// the given address, because it is loopback, link-local, private or
+// otherwise not public.
+func checkWebhookAddress(host string, ip net.IP) error {
+	if allowedWebhookHost(host) {
+		return nil
+	}
+
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("webhook host %s resolves to %s, which is not a public address", host, ip)
+	}
+
+	return nil
+}
+
+func allowedWebhookHost(host string) bool {
+	for _, allowed := range webhookHosts {
+		if strings.EqualFold(host, allowed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (sub *subscription) matches(e event) bool {
+	if len(sub.Facts) > 0 && !contains(sub.Facts, e.Fact) {
+		return false
+	}
+
+	if len(sub.Changes) == 0 && len(sub.Violations) == 0 {
+		return true
+	}
+
+	if e.Kind == "violation" {
+		return contains(sub.Violations, e.Violation)
+	}
+
+	return contains(sub.Changes, e.Kind)
+}
+
+func (sub *subscription) close() {
+	sub.closeOnce.Do(func() { close(sub.events) })
+}
+
+// deliver posts the events of a webhook subscription in order, retrying
+// failed attempts with an exponential backoff.
+func (sub *subscription) deliver() {
+	var host string
+	if u, err := url.Parse(sub.Webhook); err == nil {
+		host = u.Hostname()
+	}
+
+	// The address is checked again for every connection, as the host can
+	// resolve to another address than when the subscription was made, and
+	// redirects are not followed for the same reason
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			ip, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			return checkWebhookAddress(host, net.ParseIP(ip))
+		},
+	}
+
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	for e := range sub.events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			sub.logger.Error("cannot encode event", "error", err)
+			continue
+		}
+
+		backoff := webhookBackoff
+
+		for attempt := 1; ; attempt++ {
+			response, err := client.Post(sub.Webhook, "application/json", bytes.NewReader(data))
+			if err == nil {
+				response.Body.Close()
+				if response.StatusCode < 300 {
+					break
+				}
+				err = fmt.Errorf("status %s", response.Status)
+			}
+
+			if attempt == webhookAttempts {
+				sub.logger.Warn("dropping event for webhook", "webhook", sub.Webhook, "attempts", attempt, "error", err)
+				break
+			}
+
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+}
+
+// followsEnabled reports whether a subscription of the session receives the
+// acts that become enabled. Finding those takes a pass over all instances of
+// all acts, so this is only done if one does.
+func (s *session) followsEnabled() bool {
+	sessionsLock.Lock()
+	defer sessionsLock.Unlock()
+
+	for _, sub := range s.subscriptions {
+		if len(sub.Changes) == 0 && len(sub.Violations) == 0 || contains(sub.Changes, "enabled") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// publish sends the changes of a phrase, and the acts and violations that it
+// started, to the matching subscriptions of the session.
+func (s *session) publish(result eflint.PhraseResult, enabled []eflint.Expression, violations []eflint.Violation) {
+	events := make([]event, 0, len(result.Changes)+len(enabled)+len(violations))
+
+	for _, change := range result.Changes {
+		if !contains(changeKinds, change.Kind) || change.Operand == nil {
+			continue
+		}
+
+		events = append(events, event{
+			Session:  s.id,
+			Kind:     change.Kind,
+			Fact:     instanceName(*change.Operand),
+			Instance: *change.Operand,
+		})
+	}
+
+	for _, act := range enabled {
+		events = append(events, event{
+			Session:  s.id,
+			Kind:     "enabled",
+			Fact:     instanceName(act),
+			Instance: act,
+		})
+	}
+
+	for _, violation := range violations {
+		events = append(events, event{
+			Session:   s.id,
+			Kind:      "violation",
+			Violation: violation.Kind,
+			Fact:      violation.Identifier,
+			Instance:  eflint.Expression{Identifier: violation.Identifier, Operands: violation.Operands},
+		})
+	}
+
+	sessionsLock.Lock()
+	defer sessionsLock.Unlock()
+
+	for _, e := range events {
+		for _, sub := range s.subscriptions {
+			if !sub.matches(e) {
+				continue
+			}
+
+			select {
+			case sub.events <- e:
+			default:
+				sub.logger.Warn("dropping event for subscription, which is not keeping up")
+			}
+		}
+	}
+}
+
+// difference returns the instances that are in a but not in b.
+func difference(a []eflint.Expression, b []eflint.Expression) []eflint.Expression {
+	in := make(map[string]bool, len(b))
+	for _, instance := range b {
+		data, _ := json.Marshal(instance)
+		in[string(data)] = true
+	}
+
+	instances := make([]eflint.Expression, 0)
+	for _, instance := range a {
+		if data, _ := json.Marshal(instance); !in[string(data)] {
+			instances = append(instances, instance)
+		}
+	}
+
+	return instances
+}
+
+// violationDifference returns the violations that are in a but not in b.
+func violationDifference(a []eflint.Violation, b []eflint.Violation) []eflint.Violation {
+	in := make(map[string]bool, len(b))
+	for _, violation := range b {
+		data, _ := json.Marshal(violation)
+		in[string(data)] = true
+	}
+
+	violations := make([]eflint.Violation, 0)
+	for _, violation := range a {
+		if data, _ := json.Marshal(violation); !in[string(data)] {
+			violations = append(violations, violation)
+		}
+	}
+
+	return violations
+}
+
+// instanceName returns the name of the fact of an instance, which is a
+// reference for facts without parameters.
+func instanceName(instance eflint.Expression) string {
+	if ref, ok := instance.Value.([]string); ok && len(ref) == 1 {
+		return ref[0]
+	}
+
+	return instance.Identifier
+}
+
+// sessionsHandler serves the subscriptions of a session:
+//
+//	GET    /sessions/<id>/subscriptions         lists the webhook subscriptions
+//	POST   /sessions/<id>/subscriptions         adds a webhook subscription
+//	DELETE /sessions/<id>/subscriptions/<sub>   removes a subscription
+//	GET    /sessions/<id>/events                streams events as Server-Sent Events
+//
+// The events stream takes the same filters as a subscription as the query
+// parameters facts, changes and violations, each a comma-separated list.
+func sessionsHandler(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/sessions/"), "/"), "/")
+
+	var s *session
+	if parts[0] != "" {
+		s = openSession(parts[0])
+	}
+
+	if s == nil || len(parts) < 2 {
+		http.Error(w, "Unknown session", http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case parts[1] == "events" && len(parts) == 2 && r.Method == http.MethodGet:
+		streamEvents(w, r, s)
+	case parts[1] == "subscriptions" && len(parts) == 2 && r.Method == http.MethodGet:
+		sessionsLock.Lock()
+		subscriptions := make([]*subscription, 0, len(s.subscriptions))
+		for _, sub := range s.subscriptions {
+			if sub.Webhook != "" {
+				subscriptions = append(subscriptions, sub)
+			}
+		}
+		sessionsLock.Unlock()
+
+		writeJSON(w, http.StatusOK, subscriptions)
+	case parts[1] == "subscriptions" && len(parts) == 2 && r.Method == http.MethodPost:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			rejectInput(w, []eflint.Error{{Id: "request", Code: codeInvalidJSON, Message: err.Error()}})
+			return
+		}
+
+		if errs := validateSchema(data, "Subscription"); len(errs) > 0 {
+			rejectInput(w, errs)
+			return
+		}
+
+		sub := &subscription{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(sub); err != nil {
+			rejectInput(w, []eflint.Error{{Id: "decode", Code: codeInvalidValue, Message: err.Error()}})
+			return
+		}
+
+		if sub.Webhook == "" {
+			rejectInput(w, []eflint.Error{{
+				Id:      "subscription",
+				Code:    codeMissingField,
+				Message: "a subscription needs a webhook, use the events stream otherwise",
+				Pointer: "/webhook",
+			}})
+			return
+		}
+
+		if err := s.subscribe(sub); err != nil {
+			rejectInput(w, []eflint.Error{{Id: "subscription", Code: codeInvalidValue, Message: err.Error()}})
+			return
+		}
+
+		go sub.deliver()
+
+		writeJSON(w, http.StatusCreated, sub)
+	case parts[1] == "subscriptions" && len(parts) == 3 && r.Method == http.MethodDelete:
+		if !s.unsubscribe(parts[2]) {
+			http.Error(w, "Unknown subscription", http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+func (s *session) subscribe(sub *subscription) error {
+	if err := sub.validate(); err != nil {
+		return err
+	}
+
+	sub.ID = newID()
+	sub.events = make(chan event, subscriptionBuffer)
+	sub.logger = logger.With("session", s.id, "subscription", sub.ID)
+
+	sessionsLock.Lock()
+	s.subscriptions[sub.ID] = sub
+	sessionsLock.Unlock()
+
+	return nil
+}
+
+func (s *session) unsubscribe(id string) bool {
+	sessionsLock.Lock()
+	defer sessionsLock.Unlock()
+
+	sub, ok := s.subscriptions[id]
+	if ok {
+		sub.close()
+		delete(s.subscriptions, id)
+	}
+
+	return ok
+}
+
+// streamEvents sends the events of a session as Server-Sent Events, until the
+// client disconnects.
+func streamEvents(w http.ResponseWriter, r *http.Request, s *session) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	sub := &subscription{
+		Facts:      splitList(query.Get("facts")),
+		Changes:    splitList(query.Get("changes")),
+		Violations: splitList(query.Get("violations")),
+	}
+
+	if err := s.subscribe(sub); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer s.unsubscribe(sub.ID)
+
+	sessionsLock.Lock()
+	s.streams++
+	sessionsLock.Unlock()
+
+	defer func() {
+		sessionsLock.Lock()
+		s.streams--
+		s.lastUsed = time.Now()
+		sessionsLock.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-sub.events:
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(e)
+			if err != nil {
+				sub.logger.Error("cannot encode event", "error", err)
+				continue
+			}
+
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Kind, data)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func splitList(list string) []string {
+	items := make([]string, 0)
+
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func contains(list []string, item string) bool {
+	for _, element := range list {
+		if element == item {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,585 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/logging"
+	"github.com/gorilla/websocket"
+)
+
+// interpreterLock serialises all use of the interpreter between requests and
+// sessions, and with engines that are embedded in the same process.
+var interpreterLock = &eflint.InterpreterLock
+
+// sessionTimeout is how long a session without connections is kept, so that
+// clients can reconnect to it.
+const sessionTimeout = 30 * time.Minute
+
+// connectionBuffer is the number of messages that are queued for a
+// connection, and writeTimeout how long writing one of them may take.
+// Connections that cannot keep up are closed, so that they never hold up the
+// interpreter.
+const (
+	connectionBuffer = 256
+	writeTimeout     = 10 * time.Second
+)
+
+var (
+	errConnectionClosed = errors.New("connection closed")
+	errSlowConnection   = errors.New("connection is not keeping up")
+)
+
+// session is a normative state that is kept between messages. All
+// connections to a session receive the results of the phrases that any of
+// them sends.
+type session struct {
+	id    string
+	state eflint.State
+	// violations are those of the state, so that subscribers are only told
+	// about a violation when it starts
+	violations    []eflint.Violation
+	connections   map[sessionConn]struct{}
+	subscriptions map[string]*subscription
+	// streams is the number of open event streams, which keep the session
+	// alive like connections do
+	streams  int
+	lastUsed time.Time
+}
+
+// sessionConn is a connection to a session, over WebSocket or gRPC. Sending
+// only queues a message, which is written later on by the connection itself.
+type sessionConn interface {
+	send(message streamMessage) error
+	sendHandshake(handshake eflint.Handshake) error
+	close()
+}
+
+// outbox queues the messages of a connection, as writes that are done in
+// order by the writer of the connection. Messages are sent while the
+// interpreter is locked, so they must not wait for the client.
+type outbox struct {
+	writes    chan func() error
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newOutbox() *outbox {
+	return &outbox{
+		writes: make(chan func() error, connectionBuffer),
+		done:   make(chan struct{}),
+	}
+}
+
+// push queues a write. If the queue is full, the outbox is closed.
+func (o *outbox) push(write func() error) error {
+	select {
+	case <-o.done:
+		return errConnectionClosed
+	default:
+	}
+
+	select {
+	case o.writes <- write:
+		return nil
+	default:
+		o.close()
+		return errSlowConnection
+	}
+}
+
+// run does the queued writes until the outbox is closed or a write fails.
+func (o *outbox) run() error {
+	for {
+		select {
+		case <-o.done:
+			return nil
+		case write := <-o.writes:
+			if err := write(); err != nil {
+				o.close()
+				return err
+			}
+		}
+	}
+}
+
+// flush waits until the writes that are queued have been done.
+func (o *outbox) flush() {
+	flushed := make(chan struct{})
+	if err := o.push(func() error { close(flushed); return nil }); err != nil {
+		return
+	}
+
+	select {
+	case <-flushed:
+	case <-o.done:
+	}
+}
+
+func (o *outbox) close() {
+	o.closeOnce.Do(func() { close(o.done) })
+}
+
+type connection struct {
+	ws  *websocket.Conn
+	out *outbox
+	// formatLock protects the version and extensions, as results are also
+	// pushed by other connections to the session
+	formatLock sync.Mutex
+	// version is the version of the JSON specification of the last input of
+	// the connection, in which it receives results, with the server
+	// extensions of that input
+	version    string
+	extensions []string
+}
+
+func newConnection(ws *websocket.Conn) *connection {
+	return &connection{ws: ws, out: newOutbox()}
+}
+
+// write writes the queued messages until the connection is closed, and
+// closes it if a message cannot be written in time.
+func (c *connection) write() {
+	if err := c.out.run(); err != nil {
+		logger.Info("cannot write to WebSocket", "error", err)
+		c.ws.Close()
+	}
+}
+
+func (c *connection) send(message streamMessage) error {
+	c.formatLock.Lock()
+	data, err := message.encode(c.version, c.extensions)
+	c.formatLock.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	return c.push(data)
+}
+
+// setFormat makes the connection receive results in the version and with the
+// extensions of an input.
+func (c *connection) setFormat(input eflint.Input) {
+	c.formatLock.Lock()
+	defer c.formatLock.Unlock()
+
+	c.version = input.Version
+	c.extensions = input.Extensions
+}
+
+// sendHandshake sends the handshake as is, as it has its own format.
+func (c *connection) sendHandshake(handshake eflint.Handshake) error {
+	data, err := json.Marshal(handshake)
+	if err != nil {
+		return err
+	}
+
+	return c.push(data)
+}
+
+func (c *connection) push(data []byte) error {
+	err := c.out.push(func() error {
+		c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+		return c.ws.WriteMessage(websocket.TextMessage, data)
+	})
+	if err == errSlowConnection {
+		c.close()
+	}
+
+	return err
+}
+
+func (c *connection) close() {
+	c.out.close()
+	c.ws.Close()
+}
+
+// streamMessage is a message sent to a WebSocket client. Its kind is one of:
+//   - session: the connection is bound to the session with the given ID
+//   - result: the result of the phrase at the given index of a message
+//   - rollback: a phrase of the message failed, so the changes of the
+//     results that were sent for it are discarded
+//   - done: all phrases of a message have been run, with the output of its kind
+//   - error: the message could not be handled
+type streamMessage struct {
+	Kind    string               `json:"kind"`
+	Session string               `json:"session,omitempty"`
+	Index   *int                 `json:"index,omitempty"`
+	Result  *eflint.PhraseResult `json:"result,omitempty"`
+	Output  *eflint.Output       `json:"output,omitempty"`
+	Error   string               `json:"error,omitempty"`
+	// Errors are the reasons that an input was rejected, if it was
+	Errors []eflint.Error `json:"errors,omitempty"`
+}
+
+// errorMessage is the error message for an input that could not be handled.
+func errorMessage(err error) streamMessage {
+	message := streamMessage{Kind: "error", Error: err.Error()}
+
+	var rejected *inputError
+	if errors.As(err, &rejected) {
+		message.Errors = rejected.errs
+	}
+
+	return message
+}
+
+// encode encodes the message with its result in the given version of the JSON
+// specification, with the given server extensions.
+func (m streamMessage) encode(version string, extensions []string) ([]byte, error) {
+	if m.Result == nil {
+		return json.Marshal(m)
+	}
+
+	result, err := eflint.MarshalResult(*m.Result, version, extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	type alias streamMessage
+	return json.Marshal(struct {
+		alias
+		Result json.RawMessage `json:"result"`
+	}{alias: alias(m), Result: result})
+}
+
+var (
+	sessions     = make(map[string]*session)
+	sessionsLock sync.Mutex
+
+	upgrader = websocket.Upgrader{
+		// The server has no cookies or other credentials to protect, so
+		// browsers can connect from any origin
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+)
+
+// openSession returns the session with the given ID, or a new session if the
+// ID is empty. It returns nil if the session does not exist (anymore).
+func openSession(id string) *session {
+	if id != "" {
+		sessionsLock.Lock()
+		defer sessionsLock.Unlock()
+
+		s := sessions[id]
+		if s != nil {
+			s.lastUsed = time.Now()
+		}
+		return s
+	}
+
+	// Sessions are locked while the interpreter is locked when results are
+	// broadcast, so the interpreter cannot be locked while sessions are
+	interpreterLock.Lock()
+	eflint.Reset()
+	state := eflint.SaveState()
+	interpreterLock.Unlock()
+
+	sessionsLock.Lock()
+	defer sessionsLock.Unlock()
+
+	s := &session{
+		id:            newID(),
+		state:         state,
+		connections:   make(map[sessionConn]struct{}),
+		subscriptions: make(map[string]*subscription),
+		lastUsed:      time.Now(),
+	}
+	sessions[s.id] = s
+
+	return s
+}
+
+// newID returns a random ID for a session or subscription.
+func newID() string {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(random)
+}
+
+// expireSessions removes expired sessions every minute.
+func expireSessions() {
+	for range time.Tick(time.Minute) {
+		removeExpiredSessions()
+	}
+}
+
+// removeExpiredSessions removes sessions without connections or event streams
+// that have not been used for the session timeout.
+func removeExpiredSessions() {
+	sessionsLock.Lock()
+	defer sessionsLock.Unlock()
+
+	for id, s := range sessions {
+		if len(s.connections) == 0 && s.streams == 0 && time.Since(s.lastUsed) > sessionTimeout {
+			for _, sub := range s.subscriptions {
+				sub.close()
+			}
+			delete(sessions, id)
+			sessionInstances.DeleteLabelValues(id)
+		}
+	}
+}
+
+// websocketHandler binds a WebSocket connection to a session, which is new
+// unless the session query parameter contains the ID of an existing one. It
+// accepts the same input messages as the root path, and streams the result of
+// every phrase as soon as it has been run.
+func websocketHandler(w http.ResponseWriter, r *http.Request) {
+	s := openSession(r.URL.Query().Get("session"))
+	if s == nil {
+		http.Error(w, "Unknown session", http.StatusNotFound)
+		return
+	}
+
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already responded with an error
+		logger.Info("cannot upgrade to WebSocket", "session", s.id, "error", err)
+		return
+	}
+	defer ws.Close()
+
+	conn := newConnection(ws)
+	defer conn.close()
+	go conn.write()
+
+	sessionsLock.Lock()
+	s.connections[conn] = struct{}{}
+	sessionsLock.Unlock()
+
+	defer func() {
+		sessionsLock.Lock()
+		delete(s.connections, conn)
+		s.lastUsed = time.Now()
+		sessionsLock.Unlock()
+	}()
+
+	if err := conn.send(streamMessage{Kind: "session", Session: s.id}); err != nil {
+		return
+	}
+
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logger.Warn("WebSocket connection failed", "session", s.id, "error", err)
+			}
+			return
+		}
+
+		start := time.Now()
+		requestLogger := logger.With("session", s.id, "request", newID())
+
+		if errs := validateInput(data); len(errs) > 0 {
+			requestLogger.Info("input rejected", "error", errorsMessage(errs))
+			conn.send(errorMessage(&inputError{errs}))
+			observeRequest("websocket", invalidKind, start)
+			continue
+		}
+
+		var input eflint.Input
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&input); err != nil {
+			requestLogger.Info("input rejected", "error", err)
+			conn.send(errorMessage(&inputError{[]eflint.Error{{Id: "decode", Code: codeInvalidValue, Message: err.Error()}}}))
+			observeRequest("websocket", invalidKind, start)
+			continue
+		}
+
+		conn.setFormat(input)
+		err = s.run(conn, input, requestLogger)
+		if err != nil {
+			conn.send(errorMessage(err))
+		}
+		observeRequest("websocket", requestKind(input, err), start)
+	}
+}
+
+// run handles an input message of a connection in its session. The
+// interpreter logs with the logger of the message while it runs.
+func (s *session) run(conn sessionConn, input eflint.Input, requestLogger *logging.Logger) (err error) {
+	start := time.Now()
+
+	interpreterLock.Lock()
+	defer interpreterLock.Unlock()
+	defer useLogger(requestLogger)()
+
+	defer func() {
+		if err != nil {
+			requestLogger.Info("input failed", "kind", input.Kind, "error", err)
+			return
+		}
+		requestLogger.Debug("request handled", "kind", input.Kind, "duration", time.Since(start))
+	}()
+
+	eflint.Reset()
+	eflint.RestoreState(s.state)
+
+	// If the interpreter fails, the changes of the message are discarded, and
+	// the connections are told so if they have received results for it
+	streamed := false
+	defer func() {
+		if err != nil && streamed {
+			s.broadcast(streamMessage{Kind: "rollback"})
+		}
+	}()
+	defer recoverInterpreter(&err)
+
+	if err := eflint.Typecheck(input); err != nil {
+		return &inputError{[]eflint.Error{typecheckError(err)}}
+	}
+
+	// The acts that become enabled are only worked out for subscribers
+	followsEnabled := s.followsEnabled()
+	var enabled []eflint.Expression
+	if followsEnabled {
+		enabled = eflint.EnabledActs()
+	}
+
+	// Events are only published once the changes are kept, as subscribers
+	// cannot be told that they were discarded
+	type phraseEvents struct {
+		result     eflint.PhraseResult
+		enabled    []eflint.Expression
+		violations []eflint.Violation
+	}
+	pending := make([]phraseEvents, 0, len(input.Phrases))
+	violations := s.violations
+
+	for i, phrase := range input.Phrases {
+		err := eflint.InterpretPhrase(phrase)
+
+		results := eflint.Results()
+		result := results[len(results)-1]
+		index := i
+
+		if err != nil {
+			result.Success = false
+			result.Errors = append(result.Errors, eflint.Error{Id: "interpreter", Message: err.Error()})
+		}
+
+		var newlyEnabled []eflint.Expression
+		if followsEnabled {
+			before := enabled
+			enabled = eflint.EnabledActs()
+			newlyEnabled = difference(enabled, before)
+		}
+
+		// Queries do not check for violations, so they leave them as they are
+		var newViolations []eflint.Violation
+		if !result.IsBquery && !result.IsIquery {
+			before := violations
+			violations = result.Violations
+			newViolations = violationDifference(violations, before)
+		}
+
+		s.broadcast(streamMessage{Kind: "result", Index: &index, Result: &result})
+		streamed = true
+		pending = append(pending, phraseEvents{result, newlyEnabled, newViolations})
+	}
+
+	if input.Kind == "handshake" {
+		return conn.sendHandshake(eflint.NegotiatedHandshake(input.Version))
+	}
+
+	output, err := kindOutput(input)
+	if err != nil {
+		return err
+	}
+
+	s.state = eflint.SaveState()
+	s.violations = violations
+	sessionInstances.WithLabelValues(s.id).Set(float64(s.state.InstanceCount()))
+
+	for _, events := range pending {
+		s.publish(events.result, events.enabled, events.violations)
+	}
+
+	return conn.send(streamMessage{Kind: "done", Output: &output})
+}
+
+// errUnknownSession is returned for session IDs that do not exist (anymore).
+var errUnknownSession = errors.New("unknown session")
+
+// inspectInstances returns the instances that hold in the session with the
+// given ID. Requests outside a session start from an empty state, so without
+// an ID there is nothing to inspect. The caller must hold interpreterLock,
+// and the interpreter is reset afterwards.
+func inspectInstances(id string) ([]eflint.Expression, error) {
+	eflint.Reset()
+
+	if id == "" {
+		return []eflint.Expression{}, nil
+	}
+
+	sessionsLock.Lock()
+	s := sessions[id]
+	sessionsLock.Unlock()
+
+	if s == nil {
+		return nil, fmt.Errorf("%w %s", errUnknownSession, id)
+	}
+
+	eflint.RestoreState(s.state)
+	defer eflint.Reset()
+
+	return eflint.AllInstances(), nil
+}
+
+// kindOutput returns the output that is specific to the kind of the input,
+// such as the duties for a duties request, after its phrases have been run.
+// It fails with an inputError if the goal of an exploration does not
+// typecheck.
+func kindOutput(input eflint.Input) (eflint.Output, error) {
+	output := eflint.Output{Success: true}
+
+	switch input.Kind {
+	case "duties":
+		output.Duties = eflint.ActiveDuties()
+	case "explore":
+		traces, err := eflint.Explore(input.Depth, input.Goal)
+		if err != nil {
+			return output, &inputError{[]eflint.Error{typecheckError(err)}}
+		}
+		output.Traces = traces
+	case "graph":
+		graph := eflint.SpecGraph()
+		output.Graph = &graph
+		output.Dot = graph.DOT()
+	case "inspect":
+		output.Instances = eflint.AllInstances()
+	}
+
+	return output, nil
+}
+
+// broadcast sends a message to all connections of the session. Connections
+// that cannot keep up are closed, which ends their handler.
+func (s *session) broadcast(message streamMessage) {
+	sessionsLock.Lock()
+	connections := make([]sessionConn, 0, len(s.connections))
+	for conn := range s.connections {
+		connections = append(connections, conn)
+	}
+	sessionsLock.Unlock()
+
+	for _, conn := range connections {
+		if err := conn.send(message); err != nil {
+			conn.close()
+		}
+	}
+}
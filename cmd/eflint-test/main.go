@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/scenario"
+)
+
+func main() {
+	verbose := flag.Bool("v", false, "print a line for every scenario, not just the failing ones")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: eflint-test [-v] file.eflint|directory ...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	files, err := collectFiles(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	failed := 0
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		report, err := scenario.Run(file, src)
+		if err != nil {
+			fmt.Printf("FAIL\t%s\n\t%v\n", file, err)
+			failed++
+			continue
+		}
+
+		if !report.Passed() {
+			fmt.Printf("FAIL\t%s\n", file)
+			for _, failure := range report.Failures {
+				fmt.Println("\t" + strings.ReplaceAll(failure.String(), "\n", "\n\t"))
+			}
+			failed++
+		} else if *verbose {
+			fmt.Printf("ok\t%s\t(%d phrases, %d expectations)\n", file, report.Phrases, report.Expectations)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("FAIL\t%d of %d scenarios failed\n", failed, len(files))
+		os.Exit(1)
+	}
+
+	fmt.Printf("ok\t%d scenarios passed\n", len(files))
+}
+
+// collectFiles expands directories into the .eflint files they contain.
+func collectFiles(args []string) ([]string, error) {
+	files := make([]string, 0)
+
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !d.IsDir() && filepath.Ext(path) == ".eflint" {
+				files = append(files, path)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
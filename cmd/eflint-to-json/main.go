@@ -1,26 +1,56 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"github.com/Olaf-Erkemeij/eflint-server/internal/parser"
 	"os"
 )
 
 func main() {
+	check := flag.Bool("check", false, "validate the program and print problems instead of JSON")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: eflint-to-json [-check] [file.eflint]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	//fmt.Println(parser.String())
 	filename := ""
 	file := os.Stdin
-	if len(os.Args) > 1 {
-		f, err := os.Open(os.Args[1])
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
 		if err != nil {
 			panic(err)
 		}
 		defer f.Close()
-		filename = os.Args[1]
+		filename = flag.Arg(0)
 		file = f
 	}
 
-	result, err := parser.ParseFile(filename, file)
+	input, err := parser.Parse(filename, file)
+	if err != nil {
+		if *check {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		panic(err)
+	}
+
+	if *check {
+		diagnostics := parser.Validate(input)
+		for _, diagnostic := range diagnostics {
+			fmt.Fprintln(os.Stderr, diagnostic)
+		}
+
+		if parser.HasErrors(diagnostics) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	result, err := json.MarshalIndent(input, "", "  ")
 	if err != nil {
 		panic(err)
 	}
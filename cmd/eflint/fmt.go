@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/parser"
+)
+
+// formatMain implements eflint fmt, which formats files in place. With
+// -check, it only lists the files that are not formatted, which is useful in
+// CI. Without files, it formats standard input.
+func formatMain(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	check := flags.Bool("check", false, "list files that are not formatted instead of rewriting them, and fail if there are any")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: eflint fmt [-check] [file.eflint|directory ...]")
+		fmt.Fprintln(os.Stderr, "Formats the given files in place, or standard input when no files are given.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+
+		formatted, err := parser.Format("<stdin>", src)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+
+		if *check {
+			if !bytes.Equal(src, formatted) {
+				fmt.Println("<stdin>")
+				return 1
+			}
+			return 0
+		}
+
+		os.Stdout.Write(formatted)
+		return 0
+	}
+
+	files, err := collectFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	status := 0
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 2
+			continue
+		}
+
+		formatted, err := parser.Format(file, src)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 2
+			continue
+		}
+
+		if bytes.Equal(src, formatted) {
+			continue
+		}
+
+		if *check {
+			fmt.Println(file)
+			if status == 0 {
+				status = 1
+			}
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 2
+			continue
+		}
+
+		if err := os.WriteFile(file, formatted, info.Mode().Perm()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 2
+		}
+	}
+
+	return status
+}
+
+// collectFiles expands directories into the .eflint files they contain.
+func collectFiles(args []string) ([]string, error) {
+	files := make([]string, 0)
+
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !d.IsDir() && filepath.Ext(path) == ".eflint" {
+				files = append(files, path)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
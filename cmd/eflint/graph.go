@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+)
+
+// graphMain implements eflint graph, which prints the dependency graph of a
+// specification as Graphviz DOT or as JSON. The files are run in order, so
+// that a specification can be split over several files.
+func graphMain(args []string) int {
+	flags := flag.NewFlagSet("graph", flag.ExitOnError)
+	format := flags.String("format", "dot", "output format, dot or json")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: eflint graph [-format dot|json] file.eflint ...")
+		fmt.Fprintln(os.Stderr, "Prints the dependency graph of the declarations in the given files.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 || (*format != "dot" && *format != "json") {
+		flags.Usage()
+		return 2
+	}
+
+	eflint.Reset()
+
+	for _, filename := range flags.Args() {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+
+		input, phrases, err := load(filename, string(src))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		for i, phrase := range phrases {
+			if err := interpret(phrase); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", input.Phrases[i].Location().Pos, err)
+				return 1
+			}
+		}
+	}
+
+	graph := eflint.SpecGraph()
+
+	if *format == "dot" {
+		fmt.Print(graph.DOT())
+		return 0
+	}
+
+	data, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	fmt.Println(string(data))
+	return 0
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/lint"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/parser"
+)
+
+// lintMain implements eflint lint, which reports suspicious constructs in
+// files. It fails when there are any, apart from those of severity info.
+func lintMain(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	rules := flags.Bool("rules", false, "list the rules and exit")
+	disable := flags.String("disable", "", "comma-separated list of rules to skip")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: eflint lint [-rules] [-disable rule,...] [file.eflint|directory ...]")
+		fmt.Fprintln(os.Stderr, "Lints the given files, or standard input when no files are given.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *rules {
+		for _, rule := range lint.Rules {
+			fmt.Printf("%-24s %-8s %s\n", rule.ID, rule.Severity, rule.Description)
+		}
+		return 0
+	}
+
+	disabled := make(map[string]bool)
+	for _, id := range strings.Split(*disable, ",") {
+		if id = strings.TrimSpace(id); id == "" {
+			continue
+		}
+
+		known := false
+		for _, rule := range lint.Rules {
+			known = known || rule.ID == id
+		}
+		if !known {
+			fmt.Fprintf(os.Stderr, "unknown rule %s\n", id)
+			return 2
+		}
+
+		disabled[id] = true
+	}
+
+	status := 0
+
+	run := func(filename string, src []byte) {
+		diagnostics, err := lint.Run(filename, src)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 2
+			return
+		}
+
+		for _, diagnostic := range diagnostics {
+			if disabled[diagnostic.Rule] {
+				continue
+			}
+
+			fmt.Println(diagnostic)
+			if diagnostic.Severity != parser.SeverityInfo && status == 0 {
+				status = 1
+			}
+		}
+	}
+
+	if flags.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+
+		run("<stdin>", src)
+		return status
+	}
+
+	files, err := collectFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 2
+			continue
+		}
+
+		run(file, src)
+	}
+
+	return status
+}
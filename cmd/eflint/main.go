@@ -0,0 +1,154 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/parser"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "fmt" {
+		os.Exit(formatMain(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		os.Exit(lintMain(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "graph" {
+		os.Exit(graphMain(os.Args[2:]))
+	}
+
+	interactive := flag.Bool("i", false, "start the REPL after running the given files")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: eflint [-i] [file.eflint ...]")
+		fmt.Fprintln(os.Stderr, "       eflint fmt [-check] [file.eflint|directory ...]")
+		fmt.Fprintln(os.Stderr, "       eflint lint [-rules] [-disable rule,...] [file.eflint|directory ...]")
+		fmt.Fprintln(os.Stderr, "       eflint graph [-format dot|json] file.eflint ...")
+		fmt.Fprintln(os.Stderr, "Runs the given files, or starts the REPL when no files are given.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	eflint.SetVerbose(true)
+	eflint.Reset()
+
+	r := newREPL()
+	failed := false
+
+	for _, filename := range flag.Args() {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		if !r.run(filename, string(src)) {
+			failed = true
+		}
+	}
+
+	if *interactive || flag.NArg() == 0 {
+		if err := r.loop(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// step is a phrase that has been interpreted, together with the state from
+// just before it, so that it can be reverted.
+type step struct {
+	source string
+	state  eflint.State
+}
+
+type repl struct {
+	steps []step
+}
+
+func newREPL() *repl {
+	return &repl{steps: make([]step, 0)}
+}
+
+// run parses and interprets all phrases in src, and reports whether all of
+// them succeeded. The effect of every phrase is printed by the interpreter.
+func (r *repl) run(filename string, src string) bool {
+	input, phrases, err := load(filename, src)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+
+	ok := true
+
+	for i, phrase := range phrases {
+		location := input.Phrases[i].Location()
+		state := eflint.SaveState()
+
+		if err := interpret(phrase); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", location.Pos, err)
+			ok = false
+		}
+
+		r.steps = append(r.steps, step{
+			source: source(filename, src, location, phrase),
+			state:  state,
+		})
+	}
+
+	return ok
+}
+
+// source returns the text of a phrase as eflint fmt prints it, including the
+// final dot. A phrase that cannot be printed is given as it was written.
+func source(filename string, src string, location parser.Span, phrase eflint.Phrase) string {
+	if formatted, err := eflint.FormatPhrase(phrase); err == nil {
+		return formatted
+	}
+
+	if location.Pos.Filename == filename {
+		return strings.TrimSuffix(strings.TrimSpace(src[location.Pos.Offset:location.EndPos.Offset]), ".") + "."
+	}
+
+	return location.Pos.String()
+}
+
+// interpret runs a single phrase, turning a panic of the interpreter into an
+// error so that a single bad phrase does not end the session.
+func interpret(phrase eflint.Phrase) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("interpreter failed: %v", r)
+		}
+	}()
+
+	return eflint.InterpretPhrase(phrase)
+}
+
+// load parses src and converts its phrases to the phrases of the interpreter
+// by going through the JSON specification, just like the server does.
+func load(filename string, src string) (*parser.Input, []eflint.Phrase, error) {
+	input, err := parser.Parse(filename, strings.NewReader(src))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	phrases, err := parser.ToPhrases(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := eflint.TypecheckPhrases(phrases); err != nil {
+		return nil, nil, err
+	}
+
+	return input, phrases, nil
+}
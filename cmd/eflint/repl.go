@@ -0,0 +1,216 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+	"github.com/peterh/liner"
+)
+
+const (
+	prompt         = "eflint> "
+	continuePrompt = "   ...> "
+	historyFile    = ".eflint_history"
+)
+
+const help = `Phrases end with a dot, and can span multiple lines. An empty line also
+ends a phrase. Lines starting with a colon are commands:
+
+  :facts              list all declared facts
+  :instances [fact]   list the instances that hold, of all facts or of one fact
+  :duties             list the duties that hold, by holder and claimant
+  :history            list the phrases that have been interpreted
+  :revert [n]         undo the last n phrases (1 by default)
+  :reset              undo all phrases
+  :load file          run the phrases in a file
+  :help               show this message
+  :quit               leave the REPL`
+
+// loop reads phrases and commands from the terminal until the user quits.
+func (r *repl) loop() error {
+	line := liner.NewLiner()
+	defer line.Close()
+
+	line.SetCtrlCAborts(true)
+
+	history := filepath.Join(os.Getenv("HOME"), historyFile)
+	if f, err := os.Open(history); err == nil {
+		line.ReadHistory(f)
+		f.Close()
+	}
+
+	defer func() {
+		if f, err := os.Create(history); err == nil {
+			line.WriteHistory(f)
+			f.Close()
+		}
+	}()
+
+	fmt.Println("eFLINT", eflint.ReasonerVersion, "- type :help for help")
+
+	var buffer strings.Builder
+
+	for {
+		p := prompt
+		if buffer.Len() > 0 {
+			p = continuePrompt
+		}
+
+		input, err := line.Prompt(p)
+		if errors.Is(err, liner.ErrPromptAborted) {
+			buffer.Reset()
+			continue
+		} else if errors.Is(err, io.EOF) {
+			fmt.Println()
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if buffer.Len() == 0 && strings.HasPrefix(strings.TrimSpace(input), ":") {
+			line.AppendHistory(input)
+			if quit := r.command(strings.Fields(strings.TrimSpace(input))); quit {
+				return nil
+			}
+			continue
+		}
+
+		if buffer.Len() == 0 && strings.TrimSpace(input) == "" {
+			continue
+		}
+
+		buffer.WriteString(input)
+		buffer.WriteString("\n")
+
+		if !complete(input) {
+			continue
+		}
+
+		src := buffer.String()
+		buffer.Reset()
+
+		line.AppendHistory(strings.TrimSpace(src))
+		r.run("<stdin>", src)
+	}
+}
+
+// complete reports whether a phrase ends on the given line, which is the case
+// when the line ends with a dot or when it is empty.
+func complete(line string) bool {
+	if i := strings.Index(line, "//"); i >= 0 {
+		line = line[:i]
+	}
+
+	line = strings.TrimSpace(line)
+
+	return line == "" || strings.HasSuffix(line, ".")
+}
+
+// command executes a REPL command and reports whether the REPL should stop.
+func (r *repl) command(args []string) bool {
+	switch args[0] {
+	case ":quit", ":q", ":exit":
+		return true
+	case ":help", ":h":
+		fmt.Println(help)
+	case ":facts":
+		for _, name := range eflint.FactNames() {
+			fmt.Println(name)
+		}
+	case ":instances", ":i":
+		names := args[1:]
+		if len(names) == 0 {
+			names = eflint.FactNames()
+		}
+
+		for _, name := range names {
+			for _, instance := range eflint.Instances(name) {
+				printInstance(instance)
+			}
+		}
+	case ":duties":
+		for _, group := range eflint.ActiveDuties() {
+			fmt.Print("holder ")
+			printInstance(group.Holder)
+			fmt.Print("claimant ")
+			printInstance(group.Claimant)
+
+			for _, duty := range group.Duties {
+				fmt.Print("  ")
+				if duty.Violated {
+					fmt.Print("(VIOLATED) ")
+				}
+				printInstance(duty.Duty)
+			}
+		}
+	case ":history":
+		for i, step := range r.steps {
+			fmt.Printf("%4d  %s\n", i+1, strings.ReplaceAll(step.source, "\n", "\n      "))
+		}
+	case ":revert", ":undo":
+		n := 1
+		if len(args) > 1 {
+			var err error
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				fmt.Fprintln(os.Stderr, "usage: :revert [n]")
+				return false
+			}
+		}
+		r.revert(n)
+	case ":reset":
+		r.revert(len(r.steps))
+	case ":load":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "usage: :load file")
+			return false
+		}
+
+		src, err := os.ReadFile(args[1])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return false
+		}
+
+		r.run(args[1], string(src))
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %s, type :help for help\n", args[0])
+	}
+
+	return false
+}
+
+// revert undoes the last n phrases.
+func (r *repl) revert(n int) {
+	if n > len(r.steps) {
+		n = len(r.steps)
+	}
+
+	if n == 0 {
+		return
+	}
+
+	index := len(r.steps) - n
+	eflint.RestoreState(r.steps[index].state)
+
+	for _, step := range r.steps[index:] {
+		fmt.Println("reverted:", step.source)
+	}
+
+	r.steps = r.steps[:index]
+}
+
+func printInstance(instance eflint.Expression) {
+	formatted, err := eflint.FormatInstance(instance)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	fmt.Println(formatted)
+}
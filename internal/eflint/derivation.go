@@ -8,6 +8,7 @@ func DeriveFacts() {
 	changed := true
 
 	for changed {
+		derivationIterations++
 		changed = deriveFactsOnce()
 	}
 
@@ -22,24 +23,9 @@ func CheckViolations() {
 		if cfact, ok := fact.(CompositeFact); ok && len(cfact.ViolatedWhen) > 0 {
 			for pair := instances.Oldest(); pair != nil; pair = pair.Next() {
 				for _, violation := range cfact.ViolatedWhen {
-					clause := fillParameters(violation, cfact.IdentifiedBy, pair.Value.Operands)
-					signal := make(chan struct{})
-
-					expr, ok := <-handleExpression(clause, signal)
-					if !ok {
-						panic("Could not handle expression")
-					}
-
-					eval, err := evaluateInstance(expr)
-					if err != nil {
-						panic(err)
-					}
-
-					if eval {
+					if isViolatedBy(cfact, pair.Value, violation) {
 						addViolation("duty", pair.Value)
 					}
-
-					close(signal)
 				}
 			}
 		} else if afact, ok := fact.(AtomicFact); ok && afact.IsInvariant {
@@ -50,6 +36,38 @@ func CheckViolations() {
 	}
 }
 
+// isViolated checks whether any of the violated-when clauses of the duty holds
+// for the given instance.
+func isViolated(cfact CompositeFact, instance Expression) bool {
+	for _, violation := range cfact.ViolatedWhen {
+		if isViolatedBy(cfact, instance, violation) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isViolatedBy checks whether a single violated-when clause holds for the given
+// instance.
+func isViolatedBy(cfact CompositeFact, instance Expression, violation Expression) bool {
+	clause := fillParameters(violation, cfact.IdentifiedBy, instance.Operands)
+	signal := make(chan struct{})
+	defer close(signal)
+
+	expr, ok := <-handleExpression(clause, signal)
+	if !ok {
+		panic("Could not handle expression")
+	}
+
+	eval, err := evaluateInstance(expr)
+	if err != nil {
+		panic(err)
+	}
+
+	return eval
+}
+
 func generateDerivationRules(fact interface{}) (string, []Expression) {
 	var holdsWhen []Expression
 	var derivedFrom []Expression
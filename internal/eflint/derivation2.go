@@ -31,7 +31,9 @@ func DeriveFacts2() {
 
 	for _, fact := range globalState["facts"] {
 		name, rules := generateDerivationRules(fact)
-		dependencies[name] = make(map[string]struct{})
+		if _, ok := dependencies[name]; !ok {
+			dependencies[name] = make(map[string]struct{})
+		}
 
 		for _, rule := range rules {
 			for _, reference := range findReferences(rule) {
@@ -56,6 +58,7 @@ func DeriveFacts2() {
 	for len(queue) > 0 {
 		name := queue[0]
 		queue = queue[1:]
+		derivationIterations++
 
 		if !deriveFact2(globalState["facts"][name]) {
 			continue
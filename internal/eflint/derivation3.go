@@ -50,7 +50,9 @@ func DeriveFacts3() {
 
 	for _, fact := range globalState["facts"] {
 		name, rules := generateDerivationRules(fact)
-		dependencies[name] = make(map[string]struct{})
+		if _, ok := dependencies[name]; !ok {
+			dependencies[name] = make(map[string]struct{})
+		}
 
 		for _, rule := range rules {
 			for _, reference := range findReferences(rule) {
@@ -78,6 +80,7 @@ func DeriveFacts3() {
 	for len(globalQueue) > 0 {
 		name := globalQueue[0]
 		globalQueue = globalQueue[1:]
+		derivationIterations++
 
 		if !deriveFact3(globalState["facts"][name]) {
 			continue
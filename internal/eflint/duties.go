@@ -0,0 +1,64 @@
+package eflint
+
+import (
+	"github.com/mitchellh/hashstructure/v2"
+	"sort"
+)
+
+// ActiveDuties returns all duty instances that currently hold, grouped by
+// their holder and claimant. Duties are visited by name and then in order of
+// creation, and the groups appear in the order in which they are first found.
+func ActiveDuties() []DutyGroup {
+	groups := make([]DutyGroup, 0)
+	indices := make(map[uint64]int)
+
+	for _, name := range dutyNames() {
+		cfact := globalState["facts"][name].(CompositeFact)
+
+		for pair := globalInstances[name].Oldest(); pair != nil; pair = pair.Next() {
+			instance := copyExpression(pair.Value)
+
+			// The holder and claimant are always the first two parameters of a duty.
+			holder := instance.Operands[0]
+			claimant := instance.Operands[1]
+
+			key, err := hashstructure.Hash([]Expression{holder, claimant}, hashstructure.FormatV2, nil)
+			if err != nil {
+				panic(err)
+			}
+
+			index, ok := indices[key]
+			if !ok {
+				index = len(groups)
+				indices[key] = index
+				groups = append(groups, DutyGroup{
+					Holder:   holder,
+					Claimant: claimant,
+					Duties:   make([]DutyInstance, 0),
+				})
+			}
+
+			groups[index].Duties = append(groups[index].Duties, DutyInstance{
+				Duty:     instance,
+				Violated: isViolated(cfact, pair.Value),
+			})
+		}
+	}
+
+	return groups
+}
+
+// dutyNames returns the names of all declared duties in a deterministic order.
+func dutyNames() []string {
+	names := make([]string, 0)
+
+	for name, fact := range globalState["facts"] {
+		if cfact, ok := fact.(CompositeFact); ok && cfact.FactType == DutyType {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
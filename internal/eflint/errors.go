@@ -14,3 +14,14 @@ var ErrUnknownKind = errors.New("unknown kind")
 
 // ErrUnknownType is returned when an unknown type is provided.
 var ErrUnknownType = errors.New("unknown type")
+
+// ErrInvalidDepth is returned when a negative exploration depth is provided.
+var ErrInvalidDepth = errors.New("invalid depth")
+
+// ErrUnsupportedExtension is returned when an input asks for a server
+// extension that is not supported.
+var ErrUnsupportedExtension = errors.New("unsupported extension")
+
+// ErrInvalidGoal is returned when the goal of an exploration cannot be
+// evaluated.
+var ErrInvalidGoal = errors.New("invalid goal")
@@ -0,0 +1,271 @@
+package eflint
+
+import (
+	"fmt"
+	"github.com/mitchellh/hashstructure/v2"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"sort"
+)
+
+// DefaultExploreDepth is the number of transitions that are explored when no
+// depth is given.
+const DefaultExploreDepth = 5
+
+// MaxExploreDepth and MaxExploreStates bound an exploration, as the number of
+// reachable states can grow exponentially with the depth. Deeper explorations
+// are cut off at MaxExploreDepth, and no new states are explored once
+// MaxExploreStates states have been visited.
+var (
+	MaxExploreDepth  = 20
+	MaxExploreStates = 10000
+)
+
+// snapshot contains the part of the global state that can be changed by
+// triggering acts and events.
+type snapshot struct {
+	instances    map[string]*orderedmap.OrderedMap[uint64, Expression]
+	nonInstances map[string]*orderedmap.OrderedMap[uint64, Expression]
+}
+
+func saveSnapshot() snapshot {
+	return snapshot{
+		instances:    copyInstances(globalInstances),
+		nonInstances: copyInstances(globalNonInstances),
+	}
+}
+
+func restoreSnapshot(s snapshot) {
+	globalInstances = copyInstances(s.instances)
+	globalNonInstances = copyInstances(s.nonInstances)
+}
+
+// hashSnapshot computes a hash that is equal for snapshots with the same
+// instances and non-instances, regardless of the order in which they were
+// added.
+func hashSnapshot(s snapshot) uint64 {
+	keys := func(instances map[string]*orderedmap.OrderedMap[uint64, Expression]) map[string][]uint64 {
+		result := make(map[string][]uint64)
+
+		for factName, factInstances := range instances {
+			hashes := make([]uint64, 0, factInstances.Len())
+			for pair := factInstances.Oldest(); pair != nil; pair = pair.Next() {
+				hashes = append(hashes, pair.Key)
+			}
+			sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+			result[factName] = hashes
+		}
+
+		return result
+	}
+
+	hash, err := hashstructure.Hash(struct {
+		Instances    map[string][]uint64
+		NonInstances map[string][]uint64
+	}{keys(s.instances), keys(s.nonInstances)}, hashstructure.FormatV2, nil)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return hash
+}
+
+type exploreNode struct {
+	state snapshot
+	trace []Expression
+}
+
+// Explore searches the transition system that is reachable from the current
+// state by triggering enabled acts and events, up to the given depth. Every
+// path that leads to a violation, or to a state in which the goal holds, is
+// returned as a trace. Such paths are not explored any further, so all traces
+// are as short as possible. The depth is at most MaxExploreDepth, and at most
+// MaxExploreStates states are visited. It returns an ErrInvalidGoal if the
+// goal cannot be evaluated, such as when it names a fact that does not exist.
+func Explore(depth int, goal *Expression) ([]Trace, error) {
+	if depth <= 0 {
+		depth = DefaultExploreDepth
+	} else if depth > MaxExploreDepth {
+		logger.Warn("exploration depth cut off", "depth", depth, "max", MaxExploreDepth)
+		depth = MaxExploreDepth
+	}
+
+	traces := make([]Trace, 0)
+	initial := saveSnapshot()
+	defer restoreSnapshot(initial)
+
+	reached := func() (bool, error) {
+		if goal == nil {
+			return false, nil
+		}
+
+		holds, err := evaluateExpression(*goal)
+		if err != nil {
+			return false, fmt.Errorf("%w: %v", ErrInvalidGoal, err)
+		}
+		return holds, nil
+	}
+
+	if holds, err := reached(); err != nil || holds {
+		if err != nil {
+			return nil, err
+		}
+		return append(traces, newTrace("goal", nil, nil)), nil
+	}
+
+	visited := map[uint64]struct{}{hashSnapshot(initial): {}}
+	queue := []exploreNode{{state: initial, trace: []Expression{}}}
+	stopped := false
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		if len(node.trace) >= depth {
+			continue
+		}
+
+		restoreSnapshot(node.state)
+
+		for _, transition := range enabledTransitions(true) {
+			restoreSnapshot(node.state)
+			globalViolations = make(map[string][]Expression)
+
+			handleTrigger(copyExpression(transition))
+			deriveFacts()
+
+			trace := append(append([]Expression{}, node.trace...), transition)
+
+			if len(globalViolations) > 0 {
+				traces = append(traces, newTrace("violation", trace, globalViolations))
+				continue
+			}
+
+			holds, err := reached()
+			if err != nil {
+				return nil, err
+			}
+			if holds {
+				traces = append(traces, newTrace("goal", trace, nil))
+				continue
+			}
+
+			state := saveSnapshot()
+			hash := hashSnapshot(state)
+
+			if _, ok := visited[hash]; ok {
+				continue
+			}
+
+			if len(visited) >= MaxExploreStates {
+				if !stopped {
+					logger.Warn("exploration stopped", "states", MaxExploreStates)
+					stopped = true
+				}
+				continue
+			}
+
+			visited[hash] = struct{}{}
+			queue = append(queue, exploreNode{state: state, trace: trace})
+		}
+	}
+
+	globalViolations = make(map[string][]Expression)
+
+	return traces, nil
+}
+
+// EnabledActs returns all instances of acts that are enabled in the current
+// state, ordered by the name of their act.
+func EnabledActs() []Expression {
+	return enabledTransitions(false)
+}
+
+// enabledTransitions returns all instances of acts that are enabled and, if
+// events is set, all instances of events that can be triggered in the current
+// state.
+func enabledTransitions(events bool) []Expression {
+	names := make([]string, 0)
+
+	for name, fact := range globalState["facts"] {
+		if cfact, ok := fact.(CompositeFact); ok && (cfact.FactType == ActType || events && cfact.FactType == EventType) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	transitions := make([]Expression, 0)
+
+	for _, name := range names {
+		cfact := globalState["facts"][name].(CompositeFact)
+
+		for instance := range iterateFact(name) {
+			expr := Expression{
+				Identifier: instance.Identifier,
+				Operands:   instance.Operands,
+			}
+
+			if cfact.FactType == ActType {
+				enabled, err := evaluateExpression(Expression{Operator: "ENABLED", Operands: []Expression{expr}})
+				if err != nil {
+					logger.Warn("cannot evaluate act", "act", formatExpression(expr), "error", err)
+					continue
+				}
+				if !enabled {
+					continue
+				}
+			}
+
+			transitions = append(transitions, copyExpression(expr))
+		}
+	}
+
+	return transitions
+}
+
+// evaluateExpression reports whether the first instance of the expression
+// holds, and false if it has none.
+func evaluateExpression(expression Expression) (bool, error) {
+	expression = copyExpression(expression)
+	if err := TypeCheckExpression(&expression); err != nil {
+		return false, err
+	}
+
+	instances := gatherExpressions(expression)
+	if len(instances) == 0 {
+		return false, nil
+	}
+
+	return evaluateInstance(instances[0])
+}
+
+func newTrace(kind string, transitions []Expression, violations map[string][]Expression) Trace {
+	trace := Trace{
+		Kind:       kind,
+		Phrases:    make([]Phrase, 0, len(transitions)),
+		Violations: make([]Violation, 0),
+	}
+
+	for _, transition := range transitions {
+		operand := copyExpression(transition)
+		trace.Phrases = append(trace.Phrases, Phrase{
+			Kind:    "trigger",
+			Operand: &operand,
+		})
+	}
+
+	reasons := make([]string, 0, len(violations))
+	for reason := range violations {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	for _, reason := range reasons {
+		for _, violation := range violations[reason] {
+			trace.Violations = append(trace.Violations, toViolation(reason, violation))
+		}
+	}
+
+	return trace
+}
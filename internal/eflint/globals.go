@@ -5,8 +5,6 @@ import (
 	"reflect"
 )
 
-var SupportedVersions = []string{"0.1.0"}
-
 const Reasoner = "eflint"
 const ReasonerVersion = "3"
 const SharesUpdates = true
@@ -26,6 +24,7 @@ var DutyType = 3
 
 var defaultFacts = map[string]string{
 	"actor":  "String",
+	"bool":   "Bool",
 	"int":    "Int",
 	"ref":    "String",
 	"string": "String",
@@ -0,0 +1,245 @@
+package eflint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Graph is the structure of a specification: the declared facts, events,
+// acts and duties, and how they depend on and affect each other.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+type GraphNode struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// GraphEdge is a relation between two declarations. The kinds are:
+//   - derives: the instances of To are derived from those of From
+//   - violates: From is used to decide whether duty To is violated
+//   - creates, terminates, obfuscates: triggering From changes instances of To
+//   - syncs-with: triggering From also triggers To
+//   - actor, holder, claimant: From is the actor of act To, or the holder or
+//     claimant of duty To
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// SpecGraph returns the graph of all declarations in the current state.
+// Built-in facts only appear when another declaration refers to them. The
+// nodes and edges are sorted, so that the same specification always results
+// in the same graph.
+func SpecGraph() Graph {
+	graph := Graph{Nodes: make([]GraphNode, 0), Edges: make([]GraphEdge, 0)}
+	seen := make(map[GraphEdge]bool)
+
+	addEdge := func(from string, to string, kind string) {
+		edge := GraphEdge{From: from, To: to, Kind: kind}
+		if !seen[edge] {
+			seen[edge] = true
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+
+	for name, fact := range globalState["facts"] {
+		var derivation []Expression
+		var parameters []string
+
+		switch f := fact.(type) {
+		case AtomicFact:
+			derivation = concatExpressions(f.DerivedFrom, f.HoldsWhen, f.ConditionedBy)
+		case CompositeFact:
+			derivation = concatExpressions(f.DerivedFrom, f.HoldsWhen, f.ConditionedBy)
+			parameters = f.IdentifiedBy
+
+			for _, effect := range []struct {
+				kind        string
+				expressions []Expression
+			}{
+				{"creates", f.Creates},
+				{"terminates", f.Terminates},
+				{"obfuscates", f.Obfuscates},
+				{"syncs-with", f.SyncsWith},
+			} {
+				for _, expression := range effect.expressions {
+					for _, target := range effectTargets(expression) {
+						addEdge(name, target, effect.kind)
+					}
+				}
+			}
+
+			switch f.FactType {
+			case ActType:
+				if len(f.IdentifiedBy) > 0 {
+					addEdge(getFactName(f.IdentifiedBy[0]), name, "actor")
+				}
+			case DutyType:
+				addEdge(getFactName(f.IdentifiedBy[0]), name, "holder")
+				addEdge(getFactName(f.IdentifiedBy[1]), name, "claimant")
+
+				for _, expression := range f.ViolatedWhen {
+					for _, reference := range graphReferences(expression, parameters) {
+						addEdge(reference, name, "violates")
+					}
+				}
+			}
+		}
+
+		for _, expression := range derivation {
+			for _, reference := range graphReferences(expression, parameters) {
+				addEdge(reference, name, "derives")
+			}
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, edge := range graph.Edges {
+		used[edge.From] = true
+		used[edge.To] = true
+	}
+
+	for name, fact := range globalState["facts"] {
+		if _, ok := defaultFacts[name]; ok && !used[name] {
+			continue
+		}
+
+		kind := "fact"
+		if cfact, ok := fact.(CompositeFact); ok {
+			kind = [...]string{"fact", "event", "act", "duty"}[cfact.FactType]
+		}
+
+		graph.Nodes = append(graph.Nodes, GraphNode{Name: name, Kind: kind})
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].Name < graph.Nodes[j].Name
+	})
+
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Kind < b.Kind
+	})
+
+	return graph
+}
+
+func concatExpressions(lists ...[]Expression) []Expression {
+	all := make([]Expression, 0)
+	for _, list := range lists {
+		all = append(all, list...)
+	}
+
+	return all
+}
+
+// graphReferences returns the declared facts that an expression refers to.
+// Unlike findReferences, which only follows constructor applications, this
+// also includes variables and the facts iterated over. Variables that stand
+// for the parameters of the fact itself are left out.
+func graphReferences(expr Expression, parameters []string) []string {
+	names := findReferences(expr)
+
+	bound := make(map[string]bool)
+	for _, parameter := range parameters {
+		bound[getFactName(parameter)] = true
+	}
+
+	var walk func(Expression)
+	walk = func(expr Expression) {
+		if value, ok := expr.Value.([]string); ok && len(value) == 1 && !bound[getFactName(value[0])] {
+			names = append(names, value[0])
+		}
+
+		names = append(names, expr.Binds...)
+
+		for _, operand := range expr.Operands {
+			walk(operand)
+		}
+
+		if expr.Expression != nil {
+			walk(*expr.Expression)
+		}
+
+		if expr.Operand != nil {
+			walk(*expr.Operand)
+		}
+	}
+
+	walk(expr)
+
+	return declaredFacts(names)
+}
+
+// effectTargets returns the facts whose instances are created, terminated or
+// obfuscated by an effect, such as payment in
+// Foreach x : payment(x) When owes(x).
+func effectTargets(expr Expression) []string {
+	switch {
+	case expr.Identifier != "":
+		return declaredFacts([]string{expr.Identifier})
+	case expr.Iterator != "" && expr.Expression != nil:
+		return effectTargets(*expr.Expression)
+	case expr.Operator == "WHEN" && len(expr.Operands) > 0:
+		return effectTargets(expr.Operands[0])
+	case expr.Value != nil:
+		if value, ok := expr.Value.([]string); ok && len(value) == 1 {
+			return declaredFacts(value)
+		}
+	}
+
+	return nil
+}
+
+// declaredFacts resolves placeholders and decorations, and drops names that
+// are not declared, such as variables bound by an iterator.
+func declaredFacts(names []string) []string {
+	facts := make([]string, 0, len(names))
+
+	for _, name := range names {
+		if name = getFactName(name); globalState["facts"][name] != nil {
+			facts = append(facts, name)
+		}
+	}
+
+	return facts
+}
+
+// DOT returns the graph in the Graphviz DOT language. Facts are drawn as
+// boxes, events as ellipses, acts as hexagons and duties as octagons, and the
+// kind of every edge is used as its label.
+func (g Graph) DOT() string {
+	shapes := map[string]string{"fact": "box", "event": "ellipse", "act": "hexagon", "duty": "octagon"}
+	styles := map[string]string{"derives": "dashed", "violates": "dashed", "syncs-with": "dotted", "actor": "bold", "holder": "bold", "claimant": "bold"}
+
+	var b strings.Builder
+	b.WriteString("digraph eflint {\n")
+	b.WriteString("\trankdir=LR;\n")
+
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&b, "\t%q [shape=%s];\n", node.Name, shapes[node.Kind])
+	}
+
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "\t%q -> %q [label=%q", edge.From, edge.To, edge.Kind)
+		if style, ok := styles[edge.Kind]; ok {
+			fmt.Fprintf(&b, ", style=%s", style)
+		}
+		b.WriteString("];\n")
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"github.com/mitchellh/hashstructure/v2"
 	orderedmap "github.com/wk8/go-ordered-map/v2"
-	"log"
 	"reflect"
 	"strings"
+	"time"
 )
 
 var (
@@ -15,15 +15,12 @@ var (
 	derivationVersion = 3
 )
 
-func Println(a ...any) {
-	if verbose {
-		fmt.Println(a...)
-	}
-}
-
 func getFactName(name string) string {
-	// If the name ends with quotation marks or digits, remove those
-	name = strings.TrimRight(name, "'0123456789")
+	// If the name ends with quotation marks or digits, remove those, unless
+	// they are part of the name of a fact
+	if _, ok := globalState["facts"][name]; !ok {
+		name = strings.TrimRight(name, "'0123456789")
+	}
 
 	if globalState["placeholders"][name] != nil {
 		return getFactName(globalState["placeholders"][name].(string))
@@ -32,8 +29,9 @@ func getFactName(name string) string {
 	return name
 }
 
-// InterpretPhrases interprets the given phrases and returns the results
-func InterpretPhrases(phrases []Phrase) {
+// Reset clears the global state, results and errors, so that a new program
+// can be interpreted phrase by phrase.
+func Reset() {
 	// Clean the global result and error state
 	globalErrors = make([]Error, 0)
 	globalResults = make([]PhraseResult, 0)
@@ -44,11 +42,21 @@ func InterpretPhrases(phrases []Phrase) {
 	globalNonInstances = make(map[string]*orderedmap.OrderedMap[uint64, Expression])
 
 	initializeFacts()
+}
+
+// Results returns the results of all phrases interpreted since the last reset.
+func Results() []PhraseResult {
+	return globalResults
+}
+
+// InterpretPhrases interprets the given phrases and returns the results
+func InterpretPhrases(phrases []Phrase) {
+	Reset()
 
 	for _, phrase := range phrases {
 		if err := InterpretPhrase(phrase); err != nil {
 			// TODO: Stop after first error? Or continue?
-			log.Println(err, "oh no")
+			logger.Error("phrase failed", "kind", phrase.Kind, "error", err)
 		}
 	}
 }
@@ -69,6 +77,7 @@ func addViolation(reason string, violation Expression) {
 	}
 
 	globalViolations[reason] = append(globalViolations[reason], violation)
+	observer.Violated(reason)
 }
 
 func listViolations() {
@@ -94,44 +103,69 @@ func listViolations() {
 				Println("  violated invariant!:", formatExpression(violation))
 			}
 
-			if violation.Value != nil {
-				globalResults[index].Violations = append(globalResults[index].Violations, Violation{
-					Kind:       reason,
-					Identifier: violation.Value.([]string)[0],
-					Operands:   []Expression{}})
-			} else {
-				globalResults[index].Violations = append(globalResults[index].Violations, Violation{
-					Kind:       reason,
-					Identifier: violation.Identifier,
-					Operands:   violation.Operands})
-			}
+			globalResults[index].Violations = append(globalResults[index].Violations, toViolation(reason, violation))
 		}
 	}
 }
 
-func InterpretPhrase(phrase Phrase) error {
-	globalViolations = make(map[string][]Expression)
-	currentInstances := make(map[string]*orderedmap.OrderedMap[uint64, Expression])
-	currentNonInstances := make(map[string]*orderedmap.OrderedMap[uint64, Expression])
+func toViolation(reason string, violation Expression) Violation {
+	if violation.Value != nil {
+		return Violation{
+			Kind:       reason,
+			Identifier: violation.Value.([]string)[0],
+			Operands:   []Expression{}}
+	}
 
-	for factName, instances := range globalInstances {
-		currentInstances[factName] = orderedmap.New[uint64, Expression]()
-		for pair := instances.Oldest(); pair != nil; pair = pair.Next() {
-			currentInstances[factName].Set(pair.Key, pair.Value)
+	return Violation{
+		Kind:       reason,
+		Identifier: violation.Identifier,
+		Operands:   violation.Operands}
+}
+
+func copyInstances(instances map[string]*orderedmap.OrderedMap[uint64, Expression]) map[string]*orderedmap.OrderedMap[uint64, Expression] {
+	result := make(map[string]*orderedmap.OrderedMap[uint64, Expression])
+
+	for factName, factInstances := range instances {
+		result[factName] = orderedmap.New[uint64, Expression]()
+		for pair := factInstances.Oldest(); pair != nil; pair = pair.Next() {
+			result[factName].Set(pair.Key, pair.Value)
 		}
 	}
 
-	for factName, instances := range globalNonInstances {
-		currentNonInstances[factName] = orderedmap.New[uint64, Expression]()
-		for pair := instances.Oldest(); pair != nil; pair = pair.Next() {
-			currentNonInstances[factName].Set(pair.Key, pair.Value)
-		}
+	return result
+}
+
+func deriveFacts() {
+	start := time.Now()
+	derivationIterations = 0
+	defer func() {
+		observer.Derived(derivationStrategy(), derivationIterations, time.Since(start))
+	}()
+
+	if derivationVersion == 1 {
+		DeriveFacts()
+	} else if derivationVersion == 2 {
+		DeriveFacts2()
+	} else if derivationVersion == 3 {
+		DeriveFacts3()
+	} else {
+		panic("unknown derivation version")
 	}
+}
+
+func InterpretPhrase(phrase Phrase) error {
+	observer.PhraseInterpreted(phrase.Kind)
+	globalViolations = make(map[string][]Expression)
+	currentInstances := copyInstances(globalInstances)
 
 	globalResults = append(globalResults, PhraseResult{Success: true, Changes: []Phrase{}, Triggers: []Trigger{}, Violations: []Violation{}})
 
 	var err error = nil
 
+	defer func() {
+		auditPhrase(phrase, globalResults[len(globalResults)-1], err)
+	}()
+
 	switch phrase.Kind {
 	case "afact":
 		err = handleAtomicFact(phrase)
@@ -174,15 +208,7 @@ func InterpretPhrase(phrase Phrase) error {
 
 	index := len(globalResults) - 1
 
-	if derivationVersion == 1 {
-		DeriveFacts()
-	} else if derivationVersion == 2 {
-		DeriveFacts2()
-	} else if derivationVersion == 3 {
-		DeriveFacts3()
-	} else {
-		panic("unknown derivation version")
-	}
+	deriveFacts()
 
 	listViolations()
 
@@ -251,7 +277,8 @@ func handleExtend(phrase Phrase) error {
 		cfact.HoldsWhen = append(cfact.HoldsWhen, phrase.HoldsWhen...)
 		cfact.ConditionedBy = append(cfact.ConditionedBy, phrase.ConditionedBy...)
 
-		if cfact.FactType == EventType || cfact.FactType == ActType {
+		// Duties used to be acts, and can still be extended with effects
+		if cfact.FactType == EventType || cfact.FactType == ActType || cfact.FactType == DutyType {
 			cfact.SyncsWith = append(cfact.SyncsWith, phrase.SyncsWith...)
 			cfact.Creates = append(cfact.Creates, phrase.Creates...)
 			cfact.Terminates = append(cfact.Terminates, phrase.Terminates...)
@@ -322,7 +349,7 @@ func handleDuty(phrase Phrase) error {
 		HoldsWhen:     phrase.HoldsWhen,
 		ConditionedBy: phrase.ConditionedBy,
 		ViolatedWhen:  phrase.ViolatedWhen,
-		FactType:      ActType,
+		FactType:      DutyType,
 	})
 }
 
@@ -333,7 +360,7 @@ func handlePlaceholder(phrase Phrase) error {
 			return fmt.Errorf("placeholder %s already exists", name)
 		} else {
 			globalState["placeholders"][name] = phrase.For
-			log.Println("New placeholder:", phrase.Name, phrase.For)
+			logger.Debug("new placeholder", "name", name, "for", phrase.For)
 			globalResults[len(globalResults)-1].Changes = []Phrase{phrase}
 			return nil
 		}
@@ -365,13 +392,13 @@ func handleTrigger(operand Expression) error {
 	// Iterate over the given operand
 	for _, expr := range gatherExpressions(operand) {
 		if expr.Identifier == "" {
-			log.Println("Skipping non-identifier expression in trigger", expr)
+			logger.Warn("skipping non-identifier expression in trigger", "expression", formatExpression(expr))
 			continue
 		}
 
 		expr, err := convertInstance(expr)
 		if err != nil {
-			log.Println("Error in converting trigger instance")
+			logger.Warn("cannot convert trigger instance", "expression", formatExpression(expr), "error", err)
 			continue
 		}
 
@@ -384,7 +411,7 @@ func handleTrigger(operand Expression) error {
 					// Need to check if the fact is triggerable by checking if it holds true
 					eval, err := evaluateInstance(expr)
 					if err != nil {
-						log.Println("Error in evaluating Act")
+						logger.Warn("cannot evaluate act", "act", formatExpression(expr), "error", err)
 						continue
 					}
 
@@ -400,7 +427,7 @@ func handleTrigger(operand Expression) error {
 				} else if cfact.FactType == DutyType {
 					Println("Triggering duty", cfact.Name)
 				} else {
-					log.Println("Fact is not triggerable")
+					logger.Warn("fact is not triggerable", "fact", expr.Identifier)
 					break
 				}
 
@@ -441,10 +468,10 @@ func handleTrigger(operand Expression) error {
 					create(create1, false)
 				}
 			} else {
-				log.Println("Fact is not triggerable")
+				logger.Warn("fact is not triggerable", "fact", expr.Identifier)
 			}
 		} else {
-			log.Println("Fact not found in trigger")
+			logger.Warn("fact not found in trigger", "fact", expr.Identifier)
 		}
 	}
 
@@ -586,6 +613,8 @@ func convertAtomic(operand Expression, target string) Expression {
 			return operand
 		} else if reflect.TypeOf(operand.Value) == stringType && target == "String" {
 			return operand
+		} else if reflect.TypeOf(operand.Value) == boolType && target == "Bool" {
+			return operand
 		} else {
 			// Try to convert the value
 			if !factExists(target) {
@@ -696,7 +725,7 @@ func equalInstanceContents(instance1 Expression, instance2 Expression) bool {
 	}
 
 	if !factExists(instance1.Identifier) || !factExists(instance2.Identifier) {
-		log.Println("One of the facts does not exist")
+		logger.Warn("one of the facts does not exist", "facts", []string{instance1.Identifier, instance2.Identifier})
 		return false
 	}
 
@@ -822,7 +851,7 @@ func handleTerminate(operand Expression) error {
 func handleObfuscate(operand Expression) error {
 	for _, op := range gatherExpressions(operand) {
 		if op.Identifier == "" {
-			log.Println("Skipping non-identifier expression", formatExpression(op))
+			logger.Warn("skipping non-identifier expression", "expression", formatExpression(op))
 			continue
 		}
 
@@ -854,6 +883,10 @@ func handleObfuscate(operand Expression) error {
 func handleBQuery(expression Expression) error {
 	instances := gatherExpressions(expression)
 
+	if len(instances) == 0 {
+		return fmt.Errorf("%s has no value", formatExpression(expression))
+	}
+
 	//if len(instances) != 1 {
 	//	panic("multiple instances in handleBQuery")
 	//}
@@ -882,7 +915,7 @@ func isFiniteFact(factName string) bool {
 
 	if fact, ok := globalState["facts"][factName]; ok {
 		if afact, ok := fact.(AtomicFact); ok {
-			return len(afact.Range) > 0 || afact.Type == ""
+			return len(afact.Range) > 0 || afact.Type == "" || afact.Type == "Bool"
 		} else if cfact, ok := fact.(CompositeFact); ok {
 			for _, param := range cfact.IdentifiedBy {
 				if !isFiniteFact(param) {
@@ -911,11 +944,16 @@ func iterateFact(factName string) <-chan ConstructorApplication {
 		// Iterate over all possible instances for finite facts
 		go func() {
 			if fact, ok := globalState["facts"][factName].(AtomicFact); ok {
-				if len(fact.Range) == 0 {
+				values := fact.Range
+				if len(values) == 0 && fact.Type == "Bool" {
+					values = []Expression{{Value: false}, {Value: true}}
+				}
+
+				if len(values) == 0 {
 					c <- result
 				}
 
-				for _, instance := range fact.Range {
+				for _, instance := range values {
 					result.Operands = []Expression{
 						instance,
 					}
@@ -1015,6 +1053,9 @@ func formatExpression(expression Expression) string {
 		if expression.Operator == "NOT" {
 			return "!" + expr1
 		}
+		if keyword, ok := unaryOperators[expression.Operator]; ok {
+			return keyword + "(" + expr1 + ")"
+		}
 		expr2 := formatExpression(expression.Operands[1])
 
 		switch expression.Operator {
@@ -1048,12 +1089,12 @@ func formatExpression(expression Expression) string {
 			return expr1 + " When " + expr2
 		}
 	} else if expression.Iterator != "" {
-		keyword := expression.Iterator[:1] + strings.ToLower(expression.Iterator[1:])
-		if expression.Iterator == "EXISTS" || expression.Iterator == "FOREACH" {
-			return keyword + expression.Binds[0] + " : " + formatExpression(expression.Operands[0])
+		body := strings.Join(expression.Binds, ", ") + " : " + formatExpression(*expression.Expression)
+		if keyword, ok := aggregateIterators[expression.Iterator]; ok {
+			return keyword + "(Foreach " + body + ")"
 		}
 
-		return keyword + "(" + formatExpression(expression.Operands[0]) + ")"
+		return expression.Iterator[:1] + strings.ToLower(expression.Iterator[1:]) + " " + body
 	} else if expression.Parameter != "" {
 		return formatExpression(*expression.Operand) + "." + expression.Parameter
 	}
@@ -1061,6 +1102,30 @@ func formatExpression(expression Expression) string {
 	return ""
 }
 
+// FormatInstance formats an instance of a fact in the current state, after
+// converting its operands to the parameter types of the fact. References to a
+// fact without parameters are formatted as the name of the fact.
+func FormatInstance(instance Expression) (string, error) {
+	if ref, ok := instance.Value.([]string); ok && len(ref) == 1 {
+		return ref[0], nil
+	}
+
+	if instance.Identifier == "" {
+		return formatExpression(instance), nil
+	}
+
+	if afact, ok := globalState["facts"][instance.Identifier].(AtomicFact); ok && afact.Type == "" && len(instance.Operands) == 0 {
+		return instance.Identifier, nil
+	}
+
+	instance, err := convertInstance(copyExpression(instance))
+	if err != nil {
+		return "", err
+	}
+
+	return formatExpression(instance), nil
+}
+
 func handleIQuery(expression Expression, filter bool) error {
 	if filter {
 		Println("?--" + formatExpression(expression))
@@ -1078,6 +1143,8 @@ func handleIQuery(expression Expression, filter bool) error {
 			panic("invalid instance in iquery result")
 		}
 
+		// ?-- only results in the instances that hold, as it prints them,
+		// where ?- results in every instance of the expression
 		if filter {
 			eval, err := evaluateInstance(instance)
 			if err != nil {
@@ -1085,13 +1152,13 @@ func handleIQuery(expression Expression, filter bool) error {
 			}
 			if eval {
 				Println(formatExpression(instance))
+				results = append(results, instance)
 			}
 		} else {
 			Println(formatExpression(instance))
+			results = append(results, instance)
 		}
 
-		results = append(results, instance)
-
 		signal <- struct{}{}
 	}
 
@@ -1226,7 +1293,7 @@ func evaluateInstance(instance Expression) (bool, error) {
 			return false, nil
 		}
 	} else {
-		log.Println("Don't know what to do with instance:", instance)
+		logger.Warn("cannot evaluate instance", "instance", instance)
 	}
 
 	return false, nil
@@ -1361,16 +1428,23 @@ func handleExpression(expression Expression, signal <-chan struct{}) <-chan Expr
 
 		signal2 := make(chan struct{}, 1)
 
+		// The operands are shared with the declaration that the expression
+		// comes from, so the values are collected in a new slice
+		operands := make([]Expression, len(expression.Operands))
+
 		for i := range expression.Operands {
 			// TODO: CHeck if this is correct (It is not!)
-			expression.Operands[i], ok = <-handleExpression(expression.Operands[i], signal2)
+			operand, ok := <-handleExpression(expression.Operands[i], signal2)
 			if !ok {
 				close(signal2)
 				close(c)
 				return c
 			}
+			operands[i] = operand
 		}
 
+		expression.Operands = operands
+
 		go func() {
 			// TODO: This is needed as we cannot always evaluate instances to true/false (citizen(Bob))
 			c <- expression
@@ -1413,7 +1487,7 @@ func handleExpression(expression Expression, signal <-chan struct{}) <-chan Expr
 			close(c)
 		}()
 	} else {
-		log.Println("Unknown expression type", expression, ":(")
+		logger.Error("unknown expression type", "expression", expression)
 		panic("Unknown expression type")
 		close(c)
 	}
@@ -1429,10 +1503,23 @@ func handleArithmeticOperator(operator string, operand1 int64, operand2 int64) i
 		return operand1 - operand2
 	case "MUL":
 		return operand1 * operand2
-	case "DIV":
-		return operand1 / operand2
-	case "MOD":
-		return operand1 % operand2
+	case "DIV", "MOD":
+		if operand2 == 0 {
+			panic("Division by zero")
+		}
+
+		// Round the quotient towards negative infinity, so that the remainder
+		// has the same sign as the divisor, like the reference implementation
+		quotient, remainder := operand1/operand2, operand1%operand2
+		if remainder != 0 && (remainder < 0) != (operand2 < 0) {
+			quotient--
+			remainder += operand2
+		}
+
+		if operator == "DIV" {
+			return quotient
+		}
+		return remainder
 	case "GT":
 		return operand1 > operand2
 	case "LT":
@@ -1473,11 +1560,27 @@ func handleOperator(expression Expression, signal <-chan struct{}) <-chan Expres
 			signal1 := make(chan struct{}, 1)
 			defer close(signal1)
 
-			expression1 := <-handleExpression(expression.Operands[0], signal1)
-			expression2 := <-handleExpression(expression.Operands[1], signal1)
-
+			// An operand without a value, such as the maximum of an empty
+			// set, results in no value, for this and every other operator
+			expression1, ok := <-handleExpression(expression.Operands[0], signal1)
+			if !ok {
+				close(c)
+				return
+			}
 			expression1 = instanceToInt(expression1)
-			expression2 = instanceToInt(expression2)
+
+			// A single operand means unary minus, which is subtraction from zero
+			expression2 := expression1
+			if len(expression.Operands) == 1 && expression.Operator == "SUB" {
+				expression1 = Expression{Value: int64(0)}
+			} else {
+				expression2, ok = <-handleExpression(expression.Operands[1], signal1)
+				if !ok {
+					close(c)
+					return
+				}
+				expression2 = instanceToInt(expression2)
+			}
 
 			if expression1.Value == nil || expression2.Value == nil {
 				panic("nil value")
@@ -1501,8 +1604,12 @@ func handleOperator(expression Expression, signal <-chan struct{}) <-chan Expres
 		signal1 := make(chan struct{})
 		defer close(signal1)
 
-		expr1 := <-handleExpression(expression.Operands[0], signal1)
-		expr2 := <-handleExpression(expression.Operands[1], signal1)
+		expr1, ok1 := <-handleExpression(expression.Operands[0], signal1)
+		expr2, ok2 := <-handleExpression(expression.Operands[1], signal1)
+		if !ok1 || !ok2 {
+			close(c)
+			return c
+		}
 
 		go func() {
 			value := equalInstanceContents(expr1, expr2)
@@ -1523,7 +1630,12 @@ func handleOperator(expression Expression, signal <-chan struct{}) <-chan Expres
 
 			result := true
 			for _, operand := range expression.Operands {
-				expr := <-handleExpression(operand, signal1)
+				expr, ok := <-handleExpression(operand, signal1)
+				if !ok {
+					close(c)
+					return
+				}
+
 				if eval, err := evaluateInstance(expr); err == nil {
 					result = result && eval
 				} else {
@@ -1538,6 +1650,8 @@ func handleOperator(expression Expression, signal <-chan struct{}) <-chan Expres
 			c <- Expression{
 				Value: result,
 			}
+
+			close(c)
 		}()
 	} else if expression.Operator == "OR" {
 		go func() {
@@ -1546,7 +1660,12 @@ func handleOperator(expression Expression, signal <-chan struct{}) <-chan Expres
 
 			result := false
 			for _, operand := range expression.Operands {
-				expr := <-handleExpression(operand, signal1)
+				expr, ok := <-handleExpression(operand, signal1)
+				if !ok {
+					close(c)
+					return
+				}
+
 				if eval, err := evaluateInstance(expr); err == nil {
 					result = result || eval
 				} else {
@@ -1561,12 +1680,18 @@ func handleOperator(expression Expression, signal <-chan struct{}) <-chan Expres
 			c <- Expression{
 				Value: result,
 			}
+
+			close(c)
 		}()
 	} else if expression.Operator == "NOT" {
 		signal1 := make(chan struct{})
 		defer close(signal1)
 
-		expr := <-handleExpression(expression.Operands[0], signal1)
+		expr, ok := <-handleExpression(expression.Operands[0], signal1)
+		if !ok {
+			close(c)
+			return c
+		}
 
 		go func() {
 			if eval, err := evaluateInstance(expr); err == nil {
@@ -1606,25 +1731,9 @@ func handleOperator(expression Expression, signal <-chan struct{}) <-chan Expres
 
 			close(c)
 		}()
-	} else if expression.Operator == "COUNT" {
-		signal1 := make(chan struct{}, 1)
-
-		go func() {
-			length := int64(0)
-
-			for range handleExpression(expression.Operands[0], signal1) {
-				length++
-
-				signal1 <- struct{}{}
-			}
-
-			c <- Expression{
-				Value: length,
-			}
-
-			close(c)
-			close(signal1)
-		}()
+	} else if _, ok := aggregateIterators[expression.Operator]; ok {
+		// Aggregates can also be written as an operator around a Foreach
+		return aggregate(expression.Operator, expression.Operands[0])
 	} else if expression.Operator == "WHEN" {
 		signal1 := make(chan struct{})
 
@@ -1649,38 +1758,6 @@ func handleOperator(expression Expression, signal <-chan struct{}) <-chan Expres
 			close(c)
 			close(signal1)
 		}
-	} else if expression.Operator == "MAX" || expression.Operator == "MIN" || expression.Operator == "SUM" {
-		signal1 := make(chan struct{})
-		go func() {
-			value := int64(0)
-			first := true
-
-			for expr := range handleExpression(expression.Operands[0], signal1) {
-				numb := instanceToInt(expr)
-
-				if numb.Value == nil || reflect.TypeOf(numb.Value) != intType {
-					panic("Cannot convert to int")
-				}
-
-				if expression.Operator == "MAX" && numb.Value.(int64) > value {
-					value = numb.Value.(int64)
-				} else if expression.Operator == "MIN" && (first || numb.Value.(int64) < value) {
-					first = false
-					value = numb.Value.(int64)
-				} else if expression.Operator == "SUM" {
-					value += numb.Value.(int64)
-				}
-
-				signal1 <- struct{}{}
-			}
-
-			c <- Expression{
-				Value: value,
-			}
-
-			close(c)
-			close(signal1)
-		}()
 	} else if expression.Operator == "HOLDS" {
 		signal1 := make(chan struct{})
 		defer close(signal1)
@@ -1742,7 +1819,7 @@ func handleOperator(expression Expression, signal <-chan struct{}) <-chan Expres
 			close(c)
 		}()
 	} else {
-		log.Println("Unknown operator", expression)
+		logger.Error("unknown operator", "expression", expression)
 		panic("Unknown operator")
 	}
 
@@ -1820,14 +1897,102 @@ func handleIterator(expression Expression, signal <-chan struct{}) <-chan Expres
 
 			close(c)
 		}()
+	} else if _, ok := aggregateIterators[expression.Iterator]; ok {
+		return aggregate(expression.Iterator, *expression.Expression)
 	} else {
-		log.Println("Unknown iterator", expression)
+		logger.Error("unknown iterator", "expression", expression)
 		panic("Unknown iterator")
 	}
 
 	return c
 }
 
+// aggregateIterators maps the iterators that aggregate the values of a
+// Foreach to their keyword.
+var aggregateIterators = map[string]string{
+	"COUNT":          "Count",
+	"COUNT-DISTINCT": "Count distinct",
+	"SUM":            "Sum",
+	"MAX":            "Max",
+	"MIN":            "Min",
+	"AVG":            "Avg",
+}
+
+// aggregate computes an aggregate over all values of an expression. Count,
+// Count distinct and Sum are 0 for an empty set. Max, Min and Avg have no
+// value for an empty set, so then the channel is closed without a result, and
+// the expressions that use the aggregate have no value either. Avg is rounded
+// down.
+func aggregate(kind string, expression Expression) <-chan Expression {
+	c := make(chan Expression)
+
+	go func() {
+		signal1 := make(chan struct{}, 1)
+		defer close(signal1)
+
+		count, sum, min, max := int64(0), int64(0), int64(0), int64(0)
+		distinct := make(map[uint64]struct{})
+
+		for expr := range handleExpression(expression, signal1) {
+			switch kind {
+			case "COUNT":
+			case "COUNT-DISTINCT":
+				hash, err := hashstructure.Hash(expr, hashstructure.FormatV2, nil)
+				if err != nil {
+					panic(err)
+				}
+				distinct[hash] = struct{}{}
+			default:
+				numb := instanceToInt(expr)
+
+				if numb.Value == nil || reflect.TypeOf(numb.Value) != intType {
+					panic("Cannot convert to int")
+				}
+
+				value := numb.Value.(int64)
+				if count == 0 || value < min {
+					min = value
+				}
+				if count == 0 || value > max {
+					max = value
+				}
+				sum += value
+			}
+
+			count++
+
+			signal1 <- struct{}{}
+		}
+
+		var value int64
+
+		switch kind {
+		case "COUNT":
+			value = count
+		case "COUNT-DISTINCT":
+			value = int64(len(distinct))
+		case "SUM":
+			value = sum
+		default:
+			if count == 0 {
+				close(c)
+				return
+			}
+
+			avg := handleArithmeticOperator("DIV", sum, count).(int64)
+			value = map[string]int64{"MAX": max, "MIN": min, "AVG": avg}[kind]
+		}
+
+		c <- Expression{
+			Value: value,
+		}
+
+		close(c)
+	}()
+
+	return c
+}
+
 func handleProjection(expression Expression, signal <-chan struct{}) <-chan Expression {
 	//log.Println("Projection", expression.Parameter, expression.Operand)
 	c := make(chan Expression)
@@ -0,0 +1,62 @@
+package eflint
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/logging"
+)
+
+var (
+	logger = logging.New(os.Stderr, logging.LevelInfo, logging.FormatLogfmt)
+	audit  = false
+)
+
+// SetLogger sets the logger of the interpreter. As the interpreter is used by
+// one request at a time, a server can set a logger with the IDs of the
+// request while it holds InterpreterLock.
+func SetLogger(l *logging.Logger) {
+	if l == nil {
+		l = logging.Discard()
+	}
+
+	logger = l
+}
+
+// Logger returns the logger of the interpreter.
+func Logger() *logging.Logger {
+	return logger
+}
+
+// SetAudit enables or disables logging every phrase that is interpreted
+// together with its result, at the info level.
+func SetAudit(enabled bool) {
+	audit = enabled
+}
+
+// Println prints the effect of a phrase if verbose is enabled, and logs it
+// at the debug level.
+func Println(a ...any) {
+	if verbose {
+		fmt.Println(a...)
+	}
+
+	if logger.Enabled(logging.LevelDebug) {
+		logger.Debug(strings.TrimSpace(fmt.Sprintln(a...)))
+	}
+}
+
+// auditPhrase logs a phrase with the result that it was interpreted to.
+func auditPhrase(phrase Phrase, result PhraseResult, err error) {
+	if !audit {
+		return
+	}
+
+	if err != nil {
+		logger.Info("phrase interpreted", "kind", phrase.Kind, "phrase", phrase, "result", result, "error", err)
+		return
+	}
+
+	logger.Info("phrase interpreted", "kind", phrase.Kind, "phrase", phrase, "result", result)
+}
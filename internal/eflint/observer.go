@@ -0,0 +1,52 @@
+package eflint
+
+import "time"
+
+// Observer is told about the work of the interpreter, for example to keep
+// metrics. Its methods are called while the interpreter runs, so they must
+// return quickly.
+type Observer interface {
+	// PhraseInterpreted is called for every phrase, with its kind.
+	PhraseInterpreted(kind string)
+	// Derived is called after the facts have been derived, with the
+	// strategy, the number of iterations it took and how long it took. An
+	// iteration is a pass over all facts for DeriveFacts, and a fact taken
+	// from the queue for DeriveFacts2 and DeriveFacts3.
+	Derived(strategy string, iterations int, duration time.Duration)
+	// Violated is called for every violation, with its kind: act, duty or
+	// invariant.
+	Violated(kind string)
+}
+
+type noObserver struct{}
+
+func (noObserver) PhraseInterpreted(string)           {}
+func (noObserver) Derived(string, int, time.Duration) {}
+func (noObserver) Violated(string)                    {}
+
+var observer Observer = noObserver{}
+
+// derivationIterations counts the iterations of the current derivation.
+var derivationIterations int
+
+// SetObserver sets the observer of the interpreter, or removes it if nil.
+func SetObserver(o Observer) {
+	if o == nil {
+		o = noObserver{}
+	}
+
+	observer = o
+}
+
+// derivationStrategy returns the name of the derivation function that is
+// used, as it is reported to the observer.
+func derivationStrategy() string {
+	switch derivationVersion {
+	case 2:
+		return "DeriveFacts2"
+	case 3:
+		return "DeriveFacts3"
+	default:
+		return "DeriveFacts"
+	}
+}
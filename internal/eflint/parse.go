@@ -4,9 +4,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math/big"
 )
 
 func (i *Input) UnmarshalJSON(data []byte) error {
+	// Inputs of older versions of the specification are rewritten first
+	data, err := UpgradeInput(data)
+	if err != nil {
+		return err
+	}
+
 	type Alias Input
 	var aux Alias
 	if err := json.Unmarshal(data, &aux); err != nil {
@@ -21,10 +28,18 @@ func (i *Input) UnmarshalJSON(data []byte) error {
 	switch aux.Kind {
 	case "phrases":
 		phrasesExpected = true
+	case "duties":
+		phrasesExpected = true
+	case "explore":
+		phrasesExpected = true
+	case "graph":
+		phrasesExpected = true
 	case "handshake":
 		phrasesExpected = false
 	case "ping":
 		phrasesExpected = false
+	case "inspect":
+		phrasesExpected = false
 	default:
 		return fmt.Errorf("unknown kind: %s", aux.Kind)
 	}
@@ -43,6 +58,9 @@ func (i *Input) UnmarshalJSON(data []byte) error {
 	i.Kind = aux.Kind
 	i.Updates = aux.Updates
 	i.Phrases = aux.Phrases
+	i.Depth = aux.Depth
+	i.Extensions = aux.Extensions
+	i.Goal = aux.Goal
 
 	return nil
 }
@@ -247,14 +265,20 @@ func (p *Primitive) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
-	var Number float64
+	// Numbers without a fractional part, such as 5.0 or 5e3, are integers
+	var Number json.Number
 	if err := json.Unmarshal(data, &Number); err == nil {
-		if Number == float64(int64(Number)) {
-			p.Value = int64(Number)
+		if Integer, err := Number.Int64(); err == nil {
+			p.Value = Integer
 			return nil
-		} else {
-			return fmt.Errorf("float64 not supported")
 		}
+
+		if Rational, ok := new(big.Rat).SetString(Number.String()); ok && Rational.IsInt() && Rational.Num().IsInt64() {
+			p.Value = Rational.Num().Int64()
+			return nil
+		}
+
+		return fmt.Errorf("%s is not an integer, numbers with a fraction are not supported", Number)
 	}
 
 	var Boolean bool
@@ -266,16 +290,35 @@ func (p *Primitive) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("unknown primitive type")
 }
 
-func GenerateHandshake() ([]byte, error) {
-	return json.Marshal(Handshake{
+// GenerateHandshake generates the handshake for a handshake request of the
+// given version or range.
+func GenerateHandshake(version string) ([]byte, error) {
+	return json.Marshal(NegotiatedHandshake(version))
+}
+
+// NegotiatedHandshake returns the handshake of this reasoner, with the newest
+// supported version that satisfies the version or range of the request.
+func NegotiatedHandshake(version string) Handshake {
+	handshake := CurrentHandshake()
+	handshake.Version, _ = ResolveVersion(version)
+	return handshake
+}
+
+// CurrentHandshake returns the handshake of this reasoner.
+func CurrentHandshake() Handshake {
+	protocolsLock.RLock()
+	defer protocolsLock.RUnlock()
+
+	return Handshake{
 		Success:           true,
-		SupportedVersions: SupportedVersions,
+		SupportedVersions: SupportedVersions(),
 		Reasoner:          Reasoner,
 		ReasonerVersion:   ReasonerVersion,
 		SharesUpdates:     true,
 		SharesTriggers:    true,
 		SharesViolations:  false,
-	})
+		Extensions:        SupportedExtensions(),
+	}
 }
 
 func (e Expression) MarshalJSON() ([]byte, error) {
@@ -291,6 +334,18 @@ func (e Expression) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// Kind returns the kind of the result, which results only name with the
+// result-kind extension.
+func (p PhraseResult) Kind() string {
+	if p.IsBquery {
+		return BQueryResultKind
+	} else if p.IsIquery {
+		return IQueryResultKind
+	}
+
+	return ChangesKind
+}
+
 func (p PhraseResult) MarshalJSON() ([]byte, error) {
 
 	if p.IsBquery {
@@ -316,9 +371,9 @@ func (p PhraseResult) MarshalJSON() ([]byte, error) {
 	})
 }
 
-// GenerateJSON generates JSON from the given struct
-// If it fails, it returns an error
-func GenerateJSON(output Output) ([]byte, error) {
+// WithResults adds the results of the interpreted phrases to the output, or
+// the errors if there were any.
+func WithResults(output Output) Output {
 	if len(globalErrors) > 0 {
 		output.Success = false
 		output.Errors = globalErrors
@@ -326,7 +381,13 @@ func GenerateJSON(output Output) ([]byte, error) {
 		output.Results = globalResults
 	}
 
-	result, err := json.Marshal(output)
+	return output
+}
+
+// GenerateJSON generates JSON from the given struct
+// If it fails, it returns an error
+func GenerateJSON(output Output) ([]byte, error) {
+	result, err := json.Marshal(WithResults(output))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,441 @@
+package eflint
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BinaryOperator is the surface syntax of an operator with two operands,
+// together with the binding power of its left and right operand.
+type BinaryOperator struct {
+	Symbol      string
+	Left, Right int
+}
+
+// BinaryOperators maps the operators of the JSON specification with two
+// operands to their surface syntax. The parser and the printer share it, so
+// that a printed expression is parsed the same way again.
+var BinaryOperators = map[string]BinaryOperator{
+	"OR":  {"||", 1, 1},
+	"AND": {"&&", 1, 1},
+	"EQ":  {"==", 2, 2},
+	"NEQ": {"!=", 2, 2},
+	"ADD": {"+", 3, 3},
+	"SUB": {"-", 3, 3},
+	"MUL": {"*", 5, 4},
+	"DIV": {"/", 7, 6},
+	"MOD": {"%", 9, 8},
+	"LT":  {"<", 10, 10},
+	"GT":  {">", 10, 10},
+	"LTE": {"<=", 10, 10},
+	"GTE": {">=", 10, 10},
+}
+
+// unaryOperators maps the operators with a single operand to their keyword.
+var unaryOperators = map[string]string{
+	"NOT":     "Not",
+	"HOLDS":   "Holds",
+	"ENABLED": "Enabled",
+	"COUNT":   "Count",
+	"SUM":     "Sum",
+	"MAX":     "Max",
+	"MIN":     "Min",
+}
+
+var (
+	bareString     = regexp.MustCompile(`^[A-Z][a-zA-Z0-9_]*$`)
+	bareIdentifier = regexp.MustCompile(`^[a-z][a-zA-Z0-9_-]*'*$`)
+)
+
+// keywords contains the words that cannot be written as unquoted strings.
+var keywords = map[string]bool{
+	"Act": true, "Actor": true, "Avg": true, "Claimant": true, "Count": true, "Creates": true,
+	"Duty": true, "Enabled": true, "Event": true, "Exists": true, "Extend": true,
+	"Bool": true, "Fact": true, "False": true, "For": true, "Forall": true, "Foreach": true,
+	"Holder": true, "Holds": true, "Int": true, "Invariant": true, "Max": true,
+	"Min": true, "NOT": true, "Not": true, "Obfuscates": true, "Placeholder": true,
+	"Predicate": true, "Recipient": true, "String": true, "Sum": true,
+	"Terminates": true, "True": true, "When": true,
+}
+
+// FormatInput prints all phrases of the input as an eFLINT program, one phrase
+// per line.
+func FormatInput(input Input) (string, error) {
+	var b strings.Builder
+
+	for _, phrase := range input.Phrases {
+		formatted, err := FormatPhrase(phrase)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(formatted)
+		b.WriteString("\n")
+	}
+
+	return b.String(), nil
+}
+
+// FormatPhrase prints a phrase in eFLINT surface syntax, including the final
+// dot. The clauses of declarations are printed on separate, indented lines.
+func FormatPhrase(phrase Phrase) (string, error) {
+	var b strings.Builder
+
+	switch phrase.Kind {
+	case "bquery", "iquery":
+		prefix := "?"
+		if phrase.Kind == "iquery" {
+			prefix = "?-"
+			if phrase.WhenTrue {
+				prefix = "?--"
+			}
+		}
+
+		if phrase.Expression == nil {
+			return "", fmt.Errorf("%s without expression", phrase.Kind)
+		}
+
+		expr, err := FormatExpression(*phrase.Expression)
+		if err != nil {
+			return "", err
+		}
+
+		// Keep a minus sign from being read as part of the query prefix
+		if strings.HasPrefix(expr, "-") {
+			prefix += " "
+		}
+
+		b.WriteString(prefix + expr)
+	case "create", "terminate", "obfuscate", "trigger":
+		prefix := map[string]string{"create": "+", "terminate": "-", "obfuscate": "~", "trigger": ""}[phrase.Kind]
+
+		if phrase.Operand == nil {
+			return "", fmt.Errorf("%s without operand", phrase.Kind)
+		}
+
+		expr, err := FormatExpression(*phrase.Operand)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(prefix + expr)
+	case "afact":
+		b.WriteString("Fact " + phraseName(phrase))
+
+		if len(phrase.Range) > 0 {
+			values, err := formatExpressions(phrase.Range)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(" Identified by " + values)
+		} else if phrase.Type != "" && phrase.Type != "String" {
+			b.WriteString(" Identified by " + phrase.Type)
+		}
+	case "cfact":
+		b.WriteString("Fact " + phraseName(phrase) + " Identified by " + formatNames(phrase.IdentifiedBy, " * "))
+	case "placeholder":
+		for i, name := range phraseNames(phrase) {
+			if i > 0 {
+				b.WriteString(".\n")
+			}
+			b.WriteString("Placeholder " + formatName(name) + " For " + formatName(phrase.For))
+		}
+	case "predicate":
+		keyword := "Predicate"
+		if phrase.IsInvariant {
+			keyword = "Invariant"
+		}
+
+		if phrase.Expression == nil {
+			return "", fmt.Errorf("predicate without expression")
+		}
+
+		expr, err := FormatExpression(*phrase.Expression)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(keyword + " " + phraseName(phrase) + " When " + expr)
+	case "event":
+		b.WriteString("Event " + phraseName(phrase))
+	case "act":
+		b.WriteString("Act " + phraseName(phrase))
+		if phrase.Actor != "" && phrase.Actor != "actor" {
+			b.WriteString(" Actor " + formatName(phrase.Actor))
+		}
+	case "duty":
+		b.WriteString("Duty " + phraseName(phrase) + " Holder " + formatName(phrase.Holder) + " Claimant " + formatName(phrase.Claimant))
+	case "extend":
+		parent := phrase.ParentKind
+		if parent != "" {
+			parent = strings.ToUpper(parent[:1]) + strings.ToLower(parent[1:])
+		}
+		b.WriteString("Extend " + parent + " " + phraseName(phrase))
+	default:
+		return "", fmt.Errorf("unknown kind: %s", phrase.Kind)
+	}
+
+	if len(phrase.RelatedTo) > 0 {
+		b.WriteString(" Related to " + formatNames(phrase.RelatedTo, ", "))
+	}
+
+	clauses := []struct {
+		keyword     string
+		expressions []Expression
+	}{
+		{"Derived from", phrase.DerivedFrom},
+		{"Holds when", phrase.HoldsWhen},
+		{"Conditioned by", phrase.ConditionedBy},
+		{"Syncs with", phrase.SyncsWith},
+		{"Creates", phrase.Creates},
+		{"Terminates", phrase.Terminates},
+		{"Obfuscates", phrase.Obfuscates},
+		{"Violated when", phrase.ViolatedWhen},
+	}
+
+	for _, clause := range clauses {
+		if len(clause.expressions) == 0 {
+			continue
+		}
+
+		expressions, err := formatExpressions(clause.expressions)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString("\n  " + clause.keyword + " " + expressions)
+	}
+
+	b.WriteString(".")
+
+	return b.String(), nil
+}
+
+// FormatExpression prints an expression in eFLINT surface syntax. Parentheses
+// are only added where they are needed to parse the result back into the same
+// expression.
+func FormatExpression(expression Expression) (string, error) {
+	switch {
+	case expression.Value != nil:
+		return formatLiteral(expression.Value)
+	case expression.Identifier != "":
+		operands, err := formatExpressions(expression.Operands)
+		if err != nil {
+			return "", err
+		}
+		return formatName(expression.Identifier) + "(" + operands + ")", nil
+	case expression.Operator != "":
+		return formatOperator(expression)
+	case expression.Iterator != "":
+		return formatIterator(expression)
+	case expression.Parameter != "":
+		if expression.Operand == nil {
+			return "", fmt.Errorf("projection without operand")
+		}
+
+		operand, err := formatOperand(*expression.Operand, func(Expression) bool { return false })
+		if err != nil {
+			return "", err
+		}
+
+		return operand + "." + formatName(expression.Parameter), nil
+	}
+
+	return "", fmt.Errorf("unknown expression type")
+}
+
+func formatOperator(expression Expression) (string, error) {
+	if keyword, ok := unaryOperators[expression.Operator]; ok {
+		if len(expression.Operands) != 1 {
+			return "", fmt.Errorf("operator %s expects 1 operand", expression.Operator)
+		}
+
+		operand, err := FormatExpression(expression.Operands[0])
+		if err != nil {
+			return "", err
+		}
+
+		return keyword + "(" + operand + ")", nil
+	}
+
+	if expression.Operator == "SUB" && len(expression.Operands) == 1 {
+		operand, err := formatOperand(expression.Operands[0], func(Expression) bool { return true })
+		if err != nil {
+			return "", err
+		}
+
+		return "-" + operand, nil
+	}
+
+	if len(expression.Operands) != 2 {
+		return "", fmt.Errorf("operator %s expects 2 operands", expression.Operator)
+	}
+
+	left, right := expression.Operands[0], expression.Operands[1]
+
+	if expression.Operator == "WHEN" {
+		// The condition extends as far as possible, so only the left side can
+		// need parentheses
+		lhs, err := formatOperand(left, func(e Expression) bool { return e.Operator == "WHEN" })
+		if err != nil {
+			return "", err
+		}
+
+		rhs, err := FormatExpression(right)
+		if err != nil {
+			return "", err
+		}
+
+		return lhs + " When " + rhs, nil
+	}
+
+	operator, ok := BinaryOperators[expression.Operator]
+	if !ok {
+		return "", fmt.Errorf("unknown operator: %s", expression.Operator)
+	}
+
+	// A binary operand keeps its place without parentheses if it binds more
+	// strongly than the operator next to it.
+	lhs, err := formatOperand(left, func(e Expression) bool {
+		return BinaryOperators[e.Operator].Right <= operator.Left
+	})
+	if err != nil {
+		return "", err
+	}
+
+	rhs, err := formatOperand(right, func(e Expression) bool {
+		return BinaryOperators[e.Operator].Left < operator.Right
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return lhs + " " + operator.Symbol + " " + rhs, nil
+}
+
+func formatIterator(expression Expression) (string, error) {
+	if expression.Expression == nil {
+		return "", fmt.Errorf("iterator without expression")
+	}
+
+	expr, err := FormatExpression(*expression.Expression)
+	if err != nil {
+		return "", err
+	}
+
+	body := formatNames(expression.Binds, ", ") + " : " + expr
+	if keyword, ok := aggregateIterators[expression.Iterator]; ok {
+		return keyword + "(Foreach " + body + ")", nil
+	}
+
+	keyword := expression.Iterator[:1] + strings.ToLower(expression.Iterator[1:])
+	return keyword + " " + body, nil
+}
+
+// formatOperand prints an operand of an operator or projection. Expressions
+// that extend as far as possible (iterators, projections and conditions) are
+// always put between parentheses, and binary operators only when the given
+// function reports that they need them.
+func formatOperand(operand Expression, needsParentheses func(Expression) bool) (string, error) {
+	formatted, err := FormatExpression(operand)
+	if err != nil {
+		return "", err
+	}
+
+	_, binary := BinaryOperators[operand.Operator]
+
+	_, aggregate := aggregateIterators[operand.Iterator]
+
+	if (operand.Iterator != "" && !aggregate) || operand.Parameter != "" || operand.Operator == "WHEN" || (binary && needsParentheses(operand)) {
+		return "(" + formatted + ")", nil
+	}
+
+	return formatted, nil
+}
+
+func formatExpressions(expressions []Expression) (string, error) {
+	formatted := make([]string, 0, len(expressions))
+
+	for _, expression := range expressions {
+		expr, err := FormatExpression(expression)
+		if err != nil {
+			return "", err
+		}
+		formatted = append(formatted, expr)
+	}
+
+	return strings.Join(formatted, ", "), nil
+}
+
+func formatLiteral(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case []string:
+		if len(v) != 1 {
+			return "", fmt.Errorf("invalid variable reference")
+		}
+		return formatName(v[0]), nil
+	case string:
+		if bareString.MatchString(v) && !keywords[v] {
+			return v, nil
+		}
+		return strconv.Quote(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case bool:
+		if v {
+			return "True", nil
+		}
+		return "False", nil
+	}
+
+	return "", fmt.Errorf("unknown value: %v", value)
+}
+
+// formatName prints an identifier, using brackets for names that are not
+// valid identifiers, such as names with spaces.
+func formatName(name string) string {
+	if bareIdentifier.MatchString(name) {
+		return name
+	}
+
+	return "[" + name + "]"
+}
+
+func formatNames(names []string, separator string) string {
+	formatted := make([]string, 0, len(names))
+
+	for _, name := range names {
+		formatted = append(formatted, formatName(name))
+	}
+
+	return strings.Join(formatted, separator)
+}
+
+// phraseName returns the name of a declaration. Placeholders can have several
+// names, in which case the first one is returned.
+func phraseName(phrase Phrase) string {
+	if names := phraseNames(phrase); len(names) > 0 {
+		return formatName(names[0])
+	}
+
+	return ""
+}
+
+func phraseNames(phrase Phrase) []string {
+	switch name := phrase.Name.(type) {
+	case string:
+		return []string{name}
+	case []string:
+		return name
+	case []interface{}:
+		names := make([]string, 0, len(name))
+		for _, n := range name {
+			names = append(names, fmt.Sprint(n))
+		}
+		return names
+	}
+
+	return []string{}
+}
@@ -0,0 +1,205 @@
+package eflint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// semver is a version of the form major.minor.patch.
+type semver [3]int
+
+func parseSemver(s string) (semver, error) {
+	parts, n, err := parsePartialSemver(s)
+	if err != nil {
+		return semver{}, err
+	}
+	if n != 3 {
+		return semver{}, fmt.Errorf("%q is not of the form major.minor.patch", s)
+	}
+
+	return parts, nil
+}
+
+// parsePartialSemver parses a version that may leave out its last parts, or
+// have wildcards in their place, such as 0.1 or 0.1.x. It returns the parts
+// and how many of them were given.
+func parsePartialSemver(s string) (semver, int, error) {
+	var v semver
+
+	if s == "" {
+		return v, 0, fmt.Errorf("empty version")
+	}
+
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) > 3 {
+		return v, 0, fmt.Errorf("%q has more than three parts", s)
+	}
+
+	n := 0
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			// Everything after a wildcard is a wildcard as well
+			for _, rest := range parts[i+1:] {
+				if rest != "x" && rest != "X" && rest != "*" {
+					return v, 0, fmt.Errorf("%q has a number after a wildcard", s)
+				}
+			}
+			break
+		}
+
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return v, 0, fmt.Errorf("%q is not a version", s)
+		}
+
+		v[i] = number
+		n++
+	}
+
+	return v, n, nil
+}
+
+func (v semver) compare(w semver) int {
+	for i := range v {
+		if v[i] != w[i] {
+			if v[i] < w[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// bump returns the smallest version that is larger than all versions that
+// start with the first n parts of v.
+func (v semver) bump(n int) semver {
+	var w semver
+	copy(w[:n], v[:n])
+	w[n-1]++
+	return w
+}
+
+func (v semver) String() string {
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+// versionRange is a set of versions, written like npm version ranges:
+//   - 0.1.0 is that version only
+//   - 0.1, 0.1.x and 0.1.* are all versions that start with 0.1
+//   - ^0.1.0 is all versions that are compatible with 0.1.0, that is from
+//     0.1.0 up to the next version with a change to the first non-zero part
+//   - ~0.1.0 is all patch versions from 0.1.0 up to 0.2.0
+//   - >=, >, <= and < compare against a version, and = is the same as none
+//
+// Comparators separated by spaces or commas must all hold, and alternatives
+// are separated by ||, as in ">=0.1.0 <0.3.0 || 1.x" or ">=0.1.0, <0.3.0".
+type versionRange [][]func(semver) bool
+
+func parseVersionRange(s string) (versionRange, error) {
+	var r versionRange
+
+	for _, alternative := range strings.Split(s, "||") {
+		fields := strings.FieldsFunc(alternative, func(c rune) bool {
+			return unicode.IsSpace(c) || c == ','
+		})
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("empty range in %q", s)
+		}
+
+		comparators := make([]func(semver) bool, 0, len(fields))
+		for _, field := range fields {
+			comparator, err := parseComparator(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid range %q: %v", s, err)
+			}
+			comparators = append(comparators, comparator)
+		}
+
+		r = append(r, comparators)
+	}
+
+	return r, nil
+}
+
+func parseComparator(s string) (func(semver) bool, error) {
+	operator := strings.TrimRight(s, "0123456789.xX*v")
+	if operator == s {
+		return nil, fmt.Errorf("%q is not a version or a comparator such as >=0.1.0", s)
+	}
+
+	v, n, err := parsePartialSemver(s[len(operator):])
+	if err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		// A wildcard matches everything, whatever the operator
+		return func(semver) bool { return true }, nil
+	}
+
+	switch operator {
+	case "", "=":
+		if n == 3 {
+			return func(w semver) bool { return w == v }, nil
+		}
+		return between(v, v.bump(n)), nil
+	case "^":
+		// The first non-zero part may not change, or the last given part if
+		// they are all zero
+		i := 0
+		for i < n-1 && v[i] == 0 {
+			i++
+		}
+		return between(v, v.bump(i+1)), nil
+	case "~":
+		if n == 1 {
+			return between(v, v.bump(1)), nil
+		}
+		return between(v, v.bump(2)), nil
+	case ">=":
+		return func(w semver) bool { return w.compare(v) >= 0 }, nil
+	case ">":
+		if n == 3 {
+			return func(w semver) bool { return w.compare(v) > 0 }, nil
+		}
+		return func(w semver) bool { return w.compare(v.bump(n)) >= 0 }, nil
+	case "<=":
+		if n == 3 {
+			return func(w semver) bool { return w.compare(v) <= 0 }, nil
+		}
+		return func(w semver) bool { return w.compare(v.bump(n)) < 0 }, nil
+	case "<":
+		return func(w semver) bool { return w.compare(v) < 0 }, nil
+	default:
+		return nil, fmt.Errorf("unknown operator %q in %q", operator, s)
+	}
+}
+
+// between matches the versions from lower up to, but not including, upper.
+func between(lower semver, upper semver) func(semver) bool {
+	return func(w semver) bool {
+		return w.compare(lower) >= 0 && w.compare(upper) < 0
+	}
+}
+
+func (r versionRange) matches(v semver) bool {
+	for _, comparators := range r {
+		matches := true
+		for _, comparator := range comparators {
+			if !comparator(v) {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			return true
+		}
+	}
+
+	return false
+}
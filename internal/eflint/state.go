@@ -0,0 +1,110 @@
+package eflint
+
+import (
+	"sort"
+	"sync"
+)
+
+// InterpreterLock serialises all use of the interpreter, which keeps its
+// state in global variables, between goroutines.
+var InterpreterLock sync.Mutex
+
+// State is a copy of everything that interpreting phrases can change: the
+// declarations, the instances and the results so far.
+type State struct {
+	snapshot
+	facts        map[string]interface{}
+	placeholders map[string]interface{}
+	results      int
+}
+
+// SaveState returns a copy of the current state, which can be restored later.
+func SaveState() State {
+	return State{
+		snapshot:     saveSnapshot(),
+		facts:        copyDeclarations(globalState["facts"]),
+		placeholders: copyDeclarations(globalState["placeholders"]),
+		results:      len(globalResults),
+	}
+}
+
+// RestoreState reverts the interpreter to the given state. Results of phrases
+// that were interpreted after the state was saved are discarded.
+func RestoreState(s State) {
+	restoreSnapshot(s.snapshot)
+	globalState["facts"] = copyDeclarations(s.facts)
+	globalState["placeholders"] = copyDeclarations(s.placeholders)
+	globalViolations = make(map[string][]Expression)
+
+	if s.results < len(globalResults) {
+		globalResults = globalResults[:s.results]
+	}
+}
+
+// InstanceCount returns the number of instances that hold in the state, of
+// all facts together.
+func (s State) InstanceCount() int {
+	count := 0
+
+	for _, instances := range s.instances {
+		count += instances.Len()
+	}
+
+	return count
+}
+
+func copyDeclarations(declarations map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(declarations))
+
+	for name, declaration := range declarations {
+		result[name] = declaration
+	}
+
+	return result
+}
+
+// SetVerbose enables or disables printing the effect of every phrase.
+func SetVerbose(enabled bool) {
+	verbose = enabled
+}
+
+// FactNames returns the names of all declared facts, sorted.
+func FactNames() []string {
+	names := make([]string, 0, len(globalState["facts"]))
+
+	for name := range globalState["facts"] {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Instances returns the instances of the given fact that currently hold, in
+// order of creation.
+func Instances(factName string) []Expression {
+	instances := make([]Expression, 0)
+
+	if _, ok := globalInstances[factName]; !ok {
+		return instances
+	}
+
+	for pair := globalInstances[factName].Oldest(); pair != nil; pair = pair.Next() {
+		instances = append(instances, copyExpression(pair.Value))
+	}
+
+	return instances
+}
+
+// AllInstances returns the instances of all facts that currently hold,
+// ordered by the name of their fact and then by creation.
+func AllInstances() []Expression {
+	instances := make([]Expression, 0)
+
+	for _, name := range FactNames() {
+		instances = append(instances, Instances(name)...)
+	}
+
+	return instances
+}
@@ -5,6 +5,14 @@ type Input struct {
 	Kind    string   `json:"kind"`
 	Phrases []Phrase `json:"phrases"`
 	Updates bool     `json:"updates"`
+
+	// Exploration fields
+	Depth int         `json:"depth,omitempty"`
+	Goal  *Expression `json:"goal,omitempty"`
+
+	// Extensions are the server extensions that the input asks for, on top
+	// of the JSON specification
+	Extensions []string `json:"extensions,omitempty"`
 }
 
 // A phrase is one of 3 types:
@@ -218,11 +226,22 @@ type Output struct {
 	Errors  []Error        `json:"errors,omitempty"`
 	Results []PhraseResult `json:"results,omitempty"`
 	Phrases []Phrase       `json:"phrases,omitempty"`
+	Duties  []DutyGroup    `json:"duties,omitempty"`
+	Traces  []Trace        `json:"traces,omitempty"`
+	Graph   *Graph         `json:"graph,omitempty"`
+	Dot     string         `json:"dot,omitempty"`
+	// Instances are the instances that hold, for inspect requests
+	Instances []Expression `json:"instances,omitempty"`
 }
 
 type Error struct {
-	Id      string `json:"id"`
+	Id string `json:"id"`
+	// Code is a machine-readable reason for errors that reject an input
+	Code    string `json:"code,omitempty"`
 	Message string `json:"message"`
+	// Pointer is the JSON pointer to the field of the input that the error
+	// is about, if any
+	Pointer string `json:"pointer,omitempty"`
 }
 
 type PhraseResult struct {
@@ -238,19 +257,30 @@ type PhraseResult struct {
 	IsIquery   bool         `json:"-"`
 }
 
+// The kinds of phrase results, which results name with the result-kind
+// extension.
+const (
+	BQueryResultKind = "bquery"
+	IQueryResultKind = "iquery"
+	ChangesKind      = "changes"
+)
+
 type BQueryResult struct {
+	Kind    string  `json:"kind,omitempty"`
 	Success bool    `json:"success"`
 	Errors  []Error `json:"errors,omitempty"`
 	Result  bool    `json:"result"`
 }
 
 type IQueryResult struct {
+	Kind    string       `json:"kind,omitempty"`
 	Success bool         `json:"success"`
 	Errors  []Error      `json:"errors,omitempty"`
 	Result  []Expression `json:"result"`
 }
 
 type StateChanges struct {
+	Kind       string      `json:"kind,omitempty"`
 	Success    bool        `json:"success"`
 	Changes    []Phrase    `json:"changes"`
 	Triggers   []Trigger   `json:"triggers"`
@@ -263,6 +293,27 @@ type Result struct {
 	Errors  []Error `json:"errors,omitempty"`
 }
 
+// Duty reports
+
+type DutyGroup struct {
+	Holder   Expression     `json:"holder"`
+	Claimant Expression     `json:"claimant"`
+	Duties   []DutyInstance `json:"duties"`
+}
+
+type DutyInstance struct {
+	Duty     Expression `json:"duty"`
+	Violated bool       `json:"violated"`
+}
+
+// Exploration traces
+
+type Trace struct {
+	Kind       string      `json:"kind"`
+	Phrases    []Phrase    `json:"phrases"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
 type Handshake struct {
 	Success           bool     `json:"success"`
 	SupportedVersions []string `json:"supported_versions"`
@@ -271,6 +322,11 @@ type Handshake struct {
 	SharesUpdates     bool     `json:"shares_updates"`
 	SharesTriggers    bool     `json:"shares_triggers"`
 	SharesViolations  bool     `json:"shares_violations"`
+	// Version is the newest supported version that satisfies the version of
+	// the handshake request, which can be a range such as ^0.1.0
+	Version string `json:"version,omitempty"`
+	// Extensions are the server extensions that inputs can ask for
+	Extensions []string `json:"extensions,omitempty"`
 }
 
 type Value interface {
@@ -1,33 +1,45 @@
 package eflint
 
-func isSupportedVersion(version string) bool {
-	for _, supportedVersion := range SupportedVersions {
-		if version == supportedVersion {
-			return true
-		}
-	}
-	return false
-}
+import "fmt"
 
 // Typecheck checks that the input is valid.
 func Typecheck(input Input) error {
 	// Check if the input version is supported
-	if !isSupportedVersion(input.Version) {
-		return ErrUnsupportedVersion
+	if _, err := ResolveVersion(input.Version); err != nil {
+		return err
+	}
+
+	if err := CheckExtensions(input.Extensions); err != nil {
+		return err
+	}
+
+	// Only explorations can have a depth and a goal
+	if input.Kind != "explore" && (input.Depth != 0 || input.Goal != nil) {
+		return ErrUnsupportedFields
 	}
 
 	switch input.Kind {
 	case "phrases":
+		fallthrough
+	case "duties":
+		fallthrough
+	case "graph":
+		return TypecheckPhrases(input.Phrases)
+	case "explore":
+		if input.Depth < 0 {
+			return ErrInvalidDepth
+		}
 		return TypecheckPhrases(input.Phrases)
 	case "ping":
 		fallthrough
+	case "inspect":
+		fallthrough
 	case "handshake":
 		// Check if the input is empty
 		if len(input.Phrases) != 0 || input.Updates {
 			return ErrUnsupportedFields
 		}
 		return nil
-	// TODO: Add the "inspect" kind
 	default:
 		return ErrUnknownKind
 	}
@@ -209,8 +221,7 @@ func TypeCheckExpression(expression *Expression) error {
 
 	if expression.Identifier != "" && len(expression.Operands) == 0 {
 		if !factExists(expression.Identifier) {
-			//log.Println(expression)
-			panic("Fact does not exist in typecheck")
+			return fmt.Errorf("fact %s does not exist", expression.Identifier)
 		}
 
 		fact := globalState["facts"][expression.Identifier]
@@ -219,6 +230,12 @@ func TypeCheckExpression(expression *Expression) error {
 			for _, param := range cfact.IdentifiedBy {
 				expression.Operands = append(expression.Operands, Expression{Value: []string{param}})
 			}
+		} else if afact, ok := fact.(AtomicFact); ok && afact.Type != "" {
+			// A bare atomic fact of a type, as in amount > limit, stands for
+			// its instances like a composite fact does, so it is written as
+			// amount(amount) with a variable of its own type. Facts without a
+			// type have a single instance and are left as they are.
+			expression.Operands = append(expression.Operands, Expression{Value: []string{afact.Name}})
 		}
 	}
 
@@ -0,0 +1,262 @@
+package eflint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Protocol is a version of the JSON specification. The interpreter reads and
+// writes a single format; Upgrade rewrites an input of this version into that
+// format before it is decoded, and Downgrade rewrites the result of a phrase
+// after it has been encoded. They are nil if the version does not differ from
+// the interpreter in that direction.
+type Protocol struct {
+	Version   string
+	Upgrade   func(input map[string]interface{}) error
+	Downgrade func(result map[string]interface{}) error
+}
+
+// The interpreter reads and writes version 0.1.0 of the JSON specification.
+// Only versions that the specification has published are registered; what
+// the server adds on top of them are extensions.
+var (
+	protocols         = map[string]Protocol{"0.1.0": {Version: "0.1.0"}}
+	supportedVersions = []string{"0.1.0"}
+	protocolsLock     sync.RWMutex
+)
+
+// ResultKindExtension is the server extension in which every phrase result
+// names its kind, bquery, iquery or changes. Without it, clients tell the
+// results apart by their fields, as the JSON specification does.
+const ResultKindExtension = "result-kind"
+
+// supportedExtensions are the server extensions that inputs can ask for in
+// their extensions field, which the handshake lists.
+var supportedExtensions = []string{ResultKindExtension}
+
+// SupportedExtensions returns the server extensions that inputs can ask for.
+func SupportedExtensions() []string {
+	return append([]string(nil), supportedExtensions...)
+}
+
+// CheckExtensions returns an error if one of the extensions is not supported.
+func CheckExtensions(extensions []string) error {
+	for _, extension := range extensions {
+		if !hasExtension(supportedExtensions, extension) {
+			return fmt.Errorf("%w: %s, the supported extensions are %s",
+				ErrUnsupportedExtension, extension, strings.Join(supportedExtensions, ", "))
+		}
+	}
+
+	return nil
+}
+
+func hasExtension(extensions []string, extension string) bool {
+	for _, e := range extensions {
+		if e == extension {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SupportedVersions returns the versions of the JSON specification that have
+// a protocol, from old to new. New versions are added with RegisterProtocol.
+func SupportedVersions() []string {
+	protocolsLock.RLock()
+	defer protocolsLock.RUnlock()
+
+	return append([]string(nil), supportedVersions...)
+}
+
+// RegisterProtocol adds a version of the JSON specification, and returns a
+// function that removes it again. It panics if the version is not of the form
+// major.minor.patch or has already been registered.
+func RegisterProtocol(protocol Protocol) func() {
+	if _, err := parseSemver(protocol.Version); err != nil {
+		panic(fmt.Sprintf("eflint: invalid protocol version: %v", err))
+	}
+
+	protocolsLock.Lock()
+	defer protocolsLock.Unlock()
+
+	if _, ok := protocols[protocol.Version]; ok {
+		panic("eflint: protocol registered twice: " + protocol.Version)
+	}
+
+	protocols[protocol.Version] = protocol
+	supportedVersions = sortedVersions()
+
+	return func() {
+		protocolsLock.Lock()
+		defer protocolsLock.Unlock()
+
+		delete(protocols, protocol.Version)
+		supportedVersions = sortedVersions()
+	}
+}
+
+// sortedVersions returns the registered versions from old to new. The caller
+// must hold protocolsLock.
+func sortedVersions() []string {
+	versions := make([]string, 0, len(protocols))
+	for version := range protocols {
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		a, _ := parseSemver(versions[i])
+		b, _ := parseSemver(versions[j])
+		return a.compare(b) < 0
+	})
+
+	return versions
+}
+
+// ResolveVersion returns the newest supported version of the JSON
+// specification that satisfies the given version or range, such as 0.1.0,
+// ^0.1.0 or ">=0.1.0 <0.3.0".
+func ResolveVersion(version string) (string, error) {
+	protocolsLock.RLock()
+	defer protocolsLock.RUnlock()
+
+	if version == "" {
+		return "", fmt.Errorf("%w: the input has no version, the supported versions are %s",
+			ErrUnsupportedVersion, strings.Join(supportedVersions, ", "))
+	}
+
+	r, err := parseVersionRange(version)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v, the supported versions are %s",
+			ErrUnsupportedVersion, err, strings.Join(supportedVersions, ", "))
+	}
+
+	for i := len(supportedVersions) - 1; i >= 0; i-- {
+		v, _ := parseSemver(supportedVersions[i])
+		if r.matches(v) {
+			return supportedVersions[i], nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s, the supported versions are %s",
+		ErrUnsupportedVersion, version, strings.Join(supportedVersions, ", "))
+}
+
+// protocolFor returns the protocol of the given version or range. Inputs of
+// unsupported versions are rejected by Typecheck, so they are read and
+// written in the format of the interpreter until then.
+func protocolFor(version string) Protocol {
+	resolved, err := ResolveVersion(version)
+	if err != nil {
+		return Protocol{}
+	}
+
+	protocolsLock.RLock()
+	defer protocolsLock.RUnlock()
+
+	return protocols[resolved]
+}
+
+// UpgradeInput rewrites an encoded input into the format of the interpreter,
+// using the protocol of its version.
+func UpgradeInput(data []byte) ([]byte, error) {
+	var aux struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return nil, err
+	}
+
+	protocol := protocolFor(aux.Version)
+	if protocol.Upgrade == nil {
+		return data, nil
+	}
+
+	input, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := protocol.Upgrade(input); err != nil {
+		return nil, fmt.Errorf("version %s: %w", protocol.Version, err)
+	}
+
+	return json.Marshal(input)
+}
+
+// MarshalResult encodes the result of a phrase in the given version of the
+// JSON specification, with the given server extensions.
+func MarshalResult(result PhraseResult, version string, extensions []string) (json.RawMessage, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	protocol := protocolFor(version)
+	named := hasExtension(extensions, ResultKindExtension)
+	if protocol.Downgrade == nil && !named {
+		return data, nil
+	}
+
+	object, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if protocol.Downgrade != nil {
+		if err := protocol.Downgrade(object); err != nil {
+			return nil, fmt.Errorf("version %s: %w", protocol.Version, err)
+		}
+	}
+
+	// Extensions are added to the result in the format of its version
+	if named {
+		object["kind"] = result.Kind()
+	}
+
+	return json.Marshal(object)
+}
+
+// GenerateVersionedJSON is GenerateJSON for the given version of the JSON
+// specification, with the given server extensions.
+func GenerateVersionedJSON(output Output, version string, extensions []string) ([]byte, error) {
+	output = WithResults(output)
+
+	if protocolFor(version).Downgrade == nil && !hasExtension(extensions, ResultKindExtension) {
+		return json.Marshal(output)
+	}
+
+	type Alias Output
+	aux := struct {
+		Alias
+		Results []json.RawMessage `json:"results,omitempty"`
+	}{Alias: Alias(output)}
+
+	for _, result := range output.Results {
+		data, err := MarshalResult(result, version, extensions)
+		if err != nil {
+			return nil, err
+		}
+		aux.Results = append(aux.Results, data)
+	}
+
+	return json.Marshal(aux)
+}
+
+// decodeObject decodes a JSON object, keeping numbers as they are written.
+func decodeObject(data []byte) (map[string]interface{}, error) {
+	var object map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&object); err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
@@ -0,0 +1,308 @@
+// The gRPC API of the eFLINT server. The messages mirror the JSON
+// specification that the server accepts on its HTTP endpoint, see
+// internal/eflint/struct.go.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: eflint.proto
+
+package eflintpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Reasoner_Phrases_FullMethodName   = "/eflint.v1.Reasoner/Phrases"
+	Reasoner_Ping_FullMethodName      = "/eflint.v1.Reasoner/Ping"
+	Reasoner_Handshake_FullMethodName = "/eflint.v1.Reasoner/Handshake"
+	Reasoner_Inspect_FullMethodName   = "/eflint.v1.Reasoner/Inspect"
+	Reasoner_Session_FullMethodName   = "/eflint.v1.Reasoner/Session"
+)
+
+// ReasonerClient is the client API for Reasoner service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ReasonerClient interface {
+	// Phrases runs the phrases of an input of kind phrases, duties, explore or
+	// graph, and returns the output of that kind.
+	Phrases(ctx context.Context, in *Input, opts ...grpc.CallOption) (*Output, error)
+	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*Output, error)
+	// The message type is fully qualified, as Handshake alone is the method
+	Handshake(ctx context.Context, in *HandshakeRequest, opts ...grpc.CallOption) (*Handshake, error)
+	// Inspect returns the instances that currently hold.
+	Inspect(ctx context.Context, in *InspectRequest, opts ...grpc.CallOption) (*Output, error)
+	// Session binds the stream to a session that keeps its state between
+	// inputs, like a WebSocket connection does. The session is new, unless
+	// the eflint-session metadata contains the ID of an existing one.
+	Session(ctx context.Context, opts ...grpc.CallOption) (Reasoner_SessionClient, error)
+}
+
+type reasonerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReasonerClient(cc grpc.ClientConnInterface) ReasonerClient {
+	return &reasonerClient{cc}
+}
+
+func (c *reasonerClient) Phrases(ctx context.Context, in *Input, opts ...grpc.CallOption) (*Output, error) {
+	out := new(Output)
+	err := c.cc.Invoke(ctx, Reasoner_Phrases_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reasonerClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*Output, error) {
+	out := new(Output)
+	err := c.cc.Invoke(ctx, Reasoner_Ping_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reasonerClient) Handshake(ctx context.Context, in *HandshakeRequest, opts ...grpc.CallOption) (*Handshake, error) {
+	out := new(Handshake)
+	err := c.cc.Invoke(ctx, Reasoner_Handshake_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reasonerClient) Inspect(ctx context.Context, in *InspectRequest, opts ...grpc.CallOption) (*Output, error) {
+	out := new(Output)
+	err := c.cc.Invoke(ctx, Reasoner_Inspect_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reasonerClient) Session(ctx context.Context, opts ...grpc.CallOption) (Reasoner_SessionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Reasoner_ServiceDesc.Streams[0], Reasoner_Session_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &reasonerSessionClient{stream}
+	return x, nil
+}
+
+type Reasoner_SessionClient interface {
+	Send(*Input) error
+	Recv() (*SessionMessage, error)
+	grpc.ClientStream
+}
+
+type reasonerSessionClient struct {
+	grpc.ClientStream
+}
+
+func (x *reasonerSessionClient) Send(m *Input) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *reasonerSessionClient) Recv() (*SessionMessage, error) {
+	m := new(SessionMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ReasonerServer is the server API for Reasoner service.
+// All implementations must embed UnimplementedReasonerServer
+// for forward compatibility
+type ReasonerServer interface {
+	// Phrases runs the phrases of an input of kind phrases, duties, explore or
+	// graph, and returns the output of that kind.
+	Phrases(context.Context, *Input) (*Output, error)
+	Ping(context.Context, *PingRequest) (*Output, error)
+	// The message type is fully qualified, as Handshake alone is the method
+	Handshake(context.Context, *HandshakeRequest) (*Handshake, error)
+	// Inspect returns the instances that currently hold.
+	Inspect(context.Context, *InspectRequest) (*Output, error)
+	// Session binds the stream to a session that keeps its state between
+	// inputs, like a WebSocket connection does. The session is new, unless
+	// the eflint-session metadata contains the ID of an existing one.
+	Session(Reasoner_SessionServer) error
+	mustEmbedUnimplementedReasonerServer()
+}
+
+// UnimplementedReasonerServer must be embedded to have forward compatible implementations.
+type UnimplementedReasonerServer struct {
+}
+
+func (UnimplementedReasonerServer) Phrases(context.Context, *Input) (*Output, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Phrases not implemented")
+}
+func (UnimplementedReasonerServer) Ping(context.Context, *PingRequest) (*Output, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
+}
+func (UnimplementedReasonerServer) Handshake(context.Context, *HandshakeRequest) (*Handshake, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Handshake not implemented")
+}
+func (UnimplementedReasonerServer) Inspect(context.Context, *InspectRequest) (*Output, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Inspect not implemented")
+}
+func (UnimplementedReasonerServer) Session(Reasoner_SessionServer) error {
+	return status.Errorf(codes.Unimplemented, "method Session not implemented")
+}
+func (UnimplementedReasonerServer) mustEmbedUnimplementedReasonerServer() {}
+
+// UnsafeReasonerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReasonerServer will
+// result in compilation errors.
+type UnsafeReasonerServer interface {
+	mustEmbedUnimplementedReasonerServer()
+}
+
+func RegisterReasonerServer(s grpc.ServiceRegistrar, srv ReasonerServer) {
+	s.RegisterService(&Reasoner_ServiceDesc, srv)
+}
+
+func _Reasoner_Phrases_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Input)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReasonerServer).Phrases(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Reasoner_Phrases_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReasonerServer).Phrases(ctx, req.(*Input))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reasoner_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReasonerServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Reasoner_Ping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReasonerServer).Ping(ctx, req.(*PingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reasoner_Handshake_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandshakeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReasonerServer).Handshake(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Reasoner_Handshake_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReasonerServer).Handshake(ctx, req.(*HandshakeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reasoner_Inspect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InspectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReasonerServer).Inspect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Reasoner_Inspect_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReasonerServer).Inspect(ctx, req.(*InspectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reasoner_Session_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ReasonerServer).Session(&reasonerSessionServer{stream})
+}
+
+type Reasoner_SessionServer interface {
+	Send(*SessionMessage) error
+	Recv() (*Input, error)
+	grpc.ServerStream
+}
+
+type reasonerSessionServer struct {
+	grpc.ServerStream
+}
+
+func (x *reasonerSessionServer) Send(m *SessionMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *reasonerSessionServer) Recv() (*Input, error) {
+	m := new(Input)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Reasoner_ServiceDesc is the grpc.ServiceDesc for Reasoner service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Reasoner_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "eflint.v1.Reasoner",
+	HandlerType: (*ReasonerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Phrases",
+			Handler:    _Reasoner_Phrases_Handler,
+		},
+		{
+			MethodName: "Ping",
+			Handler:    _Reasoner_Ping_Handler,
+		},
+		{
+			MethodName: "Handshake",
+			Handler:    _Reasoner_Handshake_Handler,
+		},
+		{
+			MethodName: "Inspect",
+			Handler:    _Reasoner_Inspect_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Session",
+			Handler:       _Reasoner_Session_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "eflint.proto",
+}
@@ -0,0 +1,5 @@
+// Package eflintpb contains the protocol buffer messages and gRPC service of
+// the eFLINT server, generated from eflint.proto.
+package eflintpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative eflint.proto
@@ -0,0 +1,156 @@
+// Package lint finds constructs in eFLINT programs that are valid, but most
+// likely not what the author intended, such as facts that are never used or
+// duties that can never be violated. Every diagnostic names the rule that
+// reported it. A comment on the line before a phrase, or on one of its lines,
+// suppresses rules for that phrase:
+//
+//	// lint:ignore unused-fact, infinite-domain
+//	Fact reserved Identified by String.
+//
+// and "// lint:file-ignore rule" anywhere in a file suppresses a rule for the
+// whole file.
+package lint
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/parser"
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// Rule is a check performed by the linter.
+type Rule struct {
+	ID          string
+	Severity    parser.Severity
+	Description string
+}
+
+// Rules lists all checks, in the order in which they are run.
+var Rules = []Rule{
+	{"unused-fact", parser.SeverityWarning, "a fact is declared but never used"},
+	{"act-without-effects", parser.SeverityWarning, "an act creates, terminates, obfuscates and syncs with nothing"},
+	{"duty-without-violation", parser.SeverityInfo, "a duty has no Violated when clause, so it can never be violated"},
+	{"duty-never-terminated", parser.SeverityWarning, "a duty is not derived, and no act or event terminates it"},
+	{"unreachable-rule", parser.SeverityWarning, "a Holds when clause requires a fact that is never created or derived"},
+	{"infinite-domain", parser.SeverityWarning, "an iterator ranges over a fact without a finite domain, so it only sees known instances"},
+}
+
+// Diagnostic is a suspicious construct found by a rule.
+type Diagnostic struct {
+	Pos      lexer.Position
+	Rule     string
+	Severity parser.Severity
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", d.Pos, d.Severity, d.Message, d.Rule)
+}
+
+var suppression = regexp.MustCompile(`(?://|#|;)\s*lint:(ignore|file-ignore)\s+([a-z-]+(?:\s*,\s*[a-z-]+)*)`)
+
+// builtinFacts are declared by the interpreter, and are often redeclared
+// without being used explicitly.
+var builtinFacts = map[string]bool{"actor": true, "bool": true, "int": true, "ref": true, "string": true}
+
+// Run lints a program and returns the diagnostics for the program itself,
+// leaving out those in included files and those that are suppressed.
+func Run(filename string, src []byte) ([]Diagnostic, error) {
+	input, err := parser.Parse(filename, bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+
+	ignored, fileIgnored := collectSuppressions(src)
+
+	// The lines of the phrases in the program itself, to find the phrase
+	// that a diagnostic is reported for
+	spans := make(map[lexer.Position]parser.Span)
+	for _, phrase := range input.Phrases {
+		if span := phrase.Location(); span.Pos.Filename == filename {
+			spans[span.Pos] = span
+		}
+	}
+
+	diagnostics := make([]Diagnostic, 0)
+
+	for _, diagnostic := range Lint(input) {
+		span, ok := spans[diagnostic.Pos]
+		if !ok || fileIgnored[diagnostic.Rule] {
+			continue
+		}
+
+		suppressed := false
+		for line := span.Pos.Line - 1; line <= span.EndPos.Line; line++ {
+			if ignored[line][diagnostic.Rule] {
+				suppressed = true
+			}
+		}
+
+		if !suppressed {
+			diagnostics = append(diagnostics, diagnostic)
+		}
+	}
+
+	return diagnostics, nil
+}
+
+// collectSuppressions scans the source for suppression comments, and returns
+// the rules that are ignored by line, and those that are ignored in the whole
+// file.
+func collectSuppressions(src []byte) (map[int]map[string]bool, map[string]bool) {
+	ignored := make(map[int]map[string]bool)
+	fileIgnored := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+
+	for line := 1; scanner.Scan(); line++ {
+		match := suppression.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+
+		for _, rule := range strings.Split(match[2], ",") {
+			rule = strings.TrimSpace(rule)
+
+			if match[1] == "file-ignore" {
+				fileIgnored[rule] = true
+				continue
+			}
+
+			if ignored[line] == nil {
+				ignored[line] = make(map[string]bool)
+			}
+			ignored[line][rule] = true
+		}
+	}
+
+	return ignored, fileIgnored
+}
+
+// Lint runs all rules on a parsed program, including the phrases of included
+// files. The diagnostics are sorted by position.
+func Lint(input *parser.Input) []Diagnostic {
+	l := newLinter(input)
+
+	l.unusedFacts()
+	l.actsWithoutEffects()
+	l.dutiesWithoutViolation()
+	l.dutiesNeverTerminated()
+	l.unreachableRules()
+	l.infiniteDomains()
+
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		a, b := l.diagnostics[i].Pos, l.diagnostics[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+
+	return l.diagnostics
+}
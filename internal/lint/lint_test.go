@@ -0,0 +1,43 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	path := "tests/suspicious.eflint"
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diagnostics, err := Run(path, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The fact on line 16 is suppressed by the comment above it
+	expected := []string{
+		"2:1 unused-fact",
+		"6:1 unused-fact",
+		"6:1 unreachable-rule",
+		"9:1 act-without-effects",
+		"11:1 duty-without-violation",
+		"11:1 duty-never-terminated",
+		"18:1 unused-fact",
+		"18:1 infinite-domain",
+	}
+
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %v", len(expected), diagnostics)
+	}
+
+	for i, diagnostic := range diagnostics {
+		if got := fmt.Sprintf("%d:%d %s", diagnostic.Pos.Line, diagnostic.Pos.Column, diagnostic.Rule); got != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], got)
+		}
+	}
+}
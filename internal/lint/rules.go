@@ -0,0 +1,521 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/parser"
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// clauses are the clauses of a declaration, together with those added by
+// Extend phrases.
+type clauses struct {
+	derivedFrom  []parser.Expression
+	holdsWhen    []parser.Expression
+	creates      []parser.Expression
+	terminates   []parser.Expression
+	obfuscates   []parser.Expression
+	syncsWith    []parser.Expression
+	violatedWhen []parser.Expression
+}
+
+type linter struct {
+	input *parser.Input
+	// phrases are the declarations by name. A name that is declared twice
+	// refers to the last declaration.
+	phrases     map[string]parser.Phrase
+	clauses     map[string]*clauses
+	diagnostics []Diagnostic
+}
+
+func newLinter(input *parser.Input) *linter {
+	l := &linter{
+		input:       input,
+		phrases:     make(map[string]parser.Phrase),
+		clauses:     make(map[string]*clauses),
+		diagnostics: make([]Diagnostic, 0),
+	}
+
+	for _, phrase := range input.Phrases {
+		switch p := phrase.(type) {
+		case parser.Fact:
+			l.phrases[p.Name] = p
+			l.clausesOf(p.Name).add(clauses{derivedFrom: p.DerivedFrom, holdsWhen: p.HoldsWhen})
+		case parser.Predicate:
+			l.phrases[p.Name] = p
+		case parser.Event:
+			l.phrases[p.Name] = p
+			l.clausesOf(p.Name).add(clauses{derivedFrom: p.DerivedFrom, holdsWhen: p.HoldsWhen, creates: p.Creates, terminates: p.Terminates, obfuscates: p.Obfuscates, syncsWith: p.SyncsWith})
+		case parser.Act:
+			l.phrases[p.Name] = p
+			l.clausesOf(p.Name).add(clauses{derivedFrom: p.DerivedFrom, holdsWhen: p.HoldsWhen, creates: p.Creates, terminates: p.Terminates, obfuscates: p.Obfuscates, syncsWith: p.SyncsWith})
+		case parser.Duty:
+			l.phrases[p.Name] = p
+			l.clausesOf(p.Name).add(clauses{derivedFrom: p.DerivedFrom, holdsWhen: p.HoldsWhen, violatedWhen: p.ViolatedWhen})
+		case parser.Placeholder:
+			for _, name := range p.Name {
+				l.phrases[name] = p
+			}
+		case parser.ExtendFactDuty:
+			l.clausesOf(p.Name).add(clauses{derivedFrom: p.DerivedFrom, holdsWhen: p.HoldsWhen})
+		case parser.ExtendEventAct:
+			l.clausesOf(p.Name).add(clauses{derivedFrom: p.DerivedFrom, holdsWhen: p.HoldsWhen, creates: p.Creates, terminates: p.Terminates, obfuscates: p.Obfuscates, syncsWith: p.SyncsWith})
+		}
+	}
+
+	return l
+}
+
+func (l *linter) clausesOf(name string) *clauses {
+	if _, ok := l.clauses[name]; !ok {
+		l.clauses[name] = &clauses{}
+	}
+
+	return l.clauses[name]
+}
+
+func (c *clauses) add(other clauses) {
+	c.derivedFrom = append(c.derivedFrom, other.derivedFrom...)
+	c.holdsWhen = append(c.holdsWhen, other.holdsWhen...)
+	c.creates = append(c.creates, other.creates...)
+	c.terminates = append(c.terminates, other.terminates...)
+	c.obfuscates = append(c.obfuscates, other.obfuscates...)
+	c.syncsWith = append(c.syncsWith, other.syncsWith...)
+	c.violatedWhen = append(c.violatedWhen, other.violatedWhen...)
+}
+
+func (l *linter) report(pos lexer.Position, rule string, format string, args ...any) {
+	severity := parser.SeverityWarning
+	for _, r := range Rules {
+		if r.ID == rule {
+			severity = r.Severity
+		}
+	}
+
+	diagnostic := Diagnostic{
+		Pos:      pos,
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	}
+
+	for _, d := range l.diagnostics {
+		if d == diagnostic {
+			return
+		}
+	}
+
+	l.diagnostics = append(l.diagnostics, diagnostic)
+}
+
+// resolve returns the name of the declaration that a name refers to, by
+// removing decorations and following placeholders.
+func (l *linter) resolve(name string) string {
+	seen := make(map[string]bool)
+
+	for !seen[name] {
+		seen[name] = true
+
+		phrase, ok := l.phrases[name]
+		if !ok {
+			undecorated := strings.TrimRight(name, "'0123456789")
+			if phrase, ok = l.phrases[undecorated]; !ok {
+				return undecorated
+			}
+			name = undecorated
+		}
+
+		placeholder, ok := phrase.(parser.Placeholder)
+		if !ok {
+			return name
+		}
+
+		name = placeholder.For
+	}
+
+	return name
+}
+
+// isFact and isDuty report whether a name is declared as a fact or a duty.
+func (l *linter) isFact(name string) bool {
+	_, ok := l.phrases[name].(parser.Fact)
+	return ok
+}
+
+func (l *linter) isDuty(name string) bool {
+	_, ok := l.phrases[name].(parser.Duty)
+	return ok
+}
+
+// names returns the names that are used in an expression, resolved to the
+// declarations they refer to.
+func (l *linter) names(expression parser.Expression) []string {
+	names := make([]string, 0)
+
+	var walk func(parser.Expression)
+	walk = func(expression parser.Expression) {
+		switch e := expression.(type) {
+		case parser.Reference:
+			names = append(names, l.resolve(e.Value))
+		case parser.ConstructorApplication:
+			names = append(names, l.resolve(e.Identifier))
+			for _, operand := range e.Operands {
+				walk(operand)
+			}
+		case parser.Operator:
+			if e.Left != nil {
+				walk(e.Left)
+			}
+			if e.Right != nil {
+				walk(e.Right)
+			}
+		case parser.Iterator:
+			for _, bind := range e.Binds {
+				names = append(names, l.resolve(bind))
+			}
+			walk(e.Expression)
+		case parser.Projection:
+			names = append(names, l.resolve(e.Parameter))
+			walk(e.Operand)
+		}
+	}
+
+	walk(expression)
+
+	return names
+}
+
+// expressions returns all expressions of a phrase.
+func expressions(phrase parser.Phrase) []parser.Expression {
+	all := make([]parser.Expression, 0)
+
+	switch p := phrase.(type) {
+	case parser.Fact:
+		all = concat(p.DerivedFrom, p.HoldsWhen, p.ConditionedBy)
+	case parser.Predicate:
+		all = append(all, p.Expression)
+	case parser.Event:
+		all = concat(p.DerivedFrom, p.HoldsWhen, p.ConditionedBy, p.SyncsWith, p.Creates, p.Terminates, p.Obfuscates)
+	case parser.Act:
+		all = concat(p.DerivedFrom, p.HoldsWhen, p.ConditionedBy, p.SyncsWith, p.Creates, p.Terminates, p.Obfuscates)
+	case parser.Duty:
+		all = concat(p.DerivedFrom, p.HoldsWhen, p.ConditionedBy, p.ViolatedWhen)
+	case parser.ExtendFactDuty:
+		all = concat(p.DerivedFrom, p.HoldsWhen, p.ConditionedBy)
+	case parser.ExtendEventAct:
+		all = concat(p.DerivedFrom, p.HoldsWhen, p.ConditionedBy, p.SyncsWith, p.Creates, p.Terminates, p.Obfuscates)
+	case parser.Query:
+		all = append(all, p.Operand)
+	case parser.Statement:
+		all = append(all, p.Operand)
+	}
+
+	return all
+}
+
+func concat(lists ...[]parser.Expression) []parser.Expression {
+	all := make([]parser.Expression, 0)
+	for _, list := range lists {
+		all = append(all, list...)
+	}
+
+	return all
+}
+
+// declaredName returns the name that a phrase declares or extends.
+func declaredName(phrase parser.Phrase) string {
+	switch p := phrase.(type) {
+	case parser.Fact:
+		return p.Name
+	case parser.Predicate:
+		return p.Name
+	case parser.Event:
+		return p.Name
+	case parser.Act:
+		return p.Name
+	case parser.Duty:
+		return p.Name
+	case parser.ExtendFactDuty:
+		return p.Name
+	case parser.ExtendEventAct:
+		return p.Name
+	}
+
+	return ""
+}
+
+// unusedFacts reports facts that no other phrase refers to.
+func (l *linter) unusedFacts() {
+	used := make(map[string]bool)
+
+	for _, phrase := range l.input.Phrases {
+		self := declaredName(phrase)
+		names := make([]string, 0)
+
+		switch p := phrase.(type) {
+		case parser.Fact:
+			names = append(names, p.IdentifiedBy...)
+		case parser.Event:
+			names = append(names, p.RelatedTo...)
+		case parser.Act:
+			names = append(names, p.Actor, p.Recipient)
+			names = append(names, p.RelatedTo...)
+		case parser.Duty:
+			names = append(names, p.Holder, p.Claimant)
+			names = append(names, p.RelatedTo...)
+		case parser.Placeholder:
+			names = append(names, p.For)
+		}
+
+		for i, name := range names {
+			names[i] = l.resolve(name)
+		}
+
+		for _, expression := range expressions(phrase) {
+			names = append(names, l.names(expression)...)
+		}
+
+		for _, name := range names {
+			if name != self {
+				used[name] = true
+			}
+		}
+	}
+
+	for _, phrase := range l.input.Phrases {
+		if fact, ok := phrase.(parser.Fact); ok && !used[fact.Name] && !builtinFacts[fact.Name] {
+			l.report(fact.Pos, "unused-fact", "fact %s is never used", fact.Name)
+		}
+	}
+}
+
+// actsWithoutEffects reports acts that do not change anything.
+func (l *linter) actsWithoutEffects() {
+	for _, phrase := range l.input.Phrases {
+		act, ok := phrase.(parser.Act)
+		if !ok {
+			continue
+		}
+
+		c := l.clauses[act.Name]
+		if len(c.creates)+len(c.terminates)+len(c.obfuscates)+len(c.syncsWith) == 0 {
+			l.report(act.Pos, "act-without-effects", "act %s has no effects: it creates, terminates, obfuscates and syncs with nothing", act.Name)
+		}
+	}
+}
+
+// dutiesWithoutViolation reports duties that can never be violated.
+func (l *linter) dutiesWithoutViolation() {
+	for _, phrase := range l.input.Phrases {
+		if duty, ok := phrase.(parser.Duty); ok && len(l.clauses[duty.Name].violatedWhen) == 0 {
+			l.report(duty.Pos, "duty-without-violation", "duty %s can never be violated, as it has no Violated when clause", duty.Name)
+		}
+	}
+}
+
+// dutiesNeverTerminated reports duties that hold forever once created. Derived
+// duties end when their conditions no longer hold, so these are skipped.
+func (l *linter) dutiesNeverTerminated() {
+	terminated := make(map[string]bool)
+
+	for _, c := range l.clauses {
+		for _, expression := range c.terminates {
+			for _, name := range l.names(expression) {
+				terminated[name] = true
+			}
+		}
+	}
+
+	for _, phrase := range l.input.Phrases {
+		duty, ok := phrase.(parser.Duty)
+		if !ok {
+			continue
+		}
+
+		c := l.clauses[duty.Name]
+		if len(c.holdsWhen)+len(c.derivedFrom) == 0 && !terminated[duty.Name] {
+			l.report(duty.Pos, "duty-never-terminated", "duty %s is never terminated by an act or event", duty.Name)
+		}
+	}
+}
+
+// requires returns the facts and duties that must hold for an expression to
+// be true. Only conjunctions are followed, so the result may be incomplete
+// but never contains a fact that is not required.
+func (l *linter) requires(expression parser.Expression) []string {
+	switch e := expression.(type) {
+	case parser.ConstructorApplication:
+		if name := l.resolve(e.Identifier); l.isFact(name) || l.isDuty(name) {
+			return []string{name}
+		}
+	case parser.Operator:
+		switch e.Operator {
+		case "&&", "WHEN":
+			return append(l.requires(e.Left), l.requires(e.Right)...)
+		case "HOLDS":
+			return l.requires(e.Left)
+		}
+	}
+
+	return nil
+}
+
+// holdable returns the facts and duties of which instances can hold, because
+// they are created by an act, an event or a statement, or because they are
+// derived by a rule that can hold itself.
+func (l *linter) holdable() map[string]bool {
+	holdable := make(map[string]bool)
+
+	for name, c := range l.clauses {
+		for _, expression := range c.creates {
+			for _, created := range l.names(expression) {
+				holdable[created] = true
+			}
+		}
+
+		if len(c.derivedFrom) > 0 {
+			holdable[name] = true
+		}
+	}
+
+	for _, phrase := range l.input.Phrases {
+		if statement, ok := phrase.(parser.Statement); ok && statement.Kind == "create" {
+			for _, created := range l.names(statement.Operand) {
+				holdable[created] = true
+			}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for name, c := range l.clauses {
+			if holdable[name] {
+				continue
+			}
+
+			for _, expression := range c.holdsWhen {
+				if l.missing(expression, holdable) == "" {
+					holdable[name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	return holdable
+}
+
+// missing returns a fact that an expression requires, but that can never
+// hold, or an empty string.
+func (l *linter) missing(expression parser.Expression, holdable map[string]bool) string {
+	for _, name := range l.requires(expression) {
+		if !holdable[name] {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// unreachableRules reports Holds when clauses that can never be true.
+func (l *linter) unreachableRules() {
+	holdable := l.holdable()
+
+	for _, phrase := range l.input.Phrases {
+		var holdsWhen []parser.Expression
+
+		switch p := phrase.(type) {
+		case parser.Fact:
+			holdsWhen = p.HoldsWhen
+		case parser.Event:
+			holdsWhen = p.HoldsWhen
+		case parser.Act:
+			holdsWhen = p.HoldsWhen
+		case parser.Duty:
+			holdsWhen = p.HoldsWhen
+		case parser.ExtendFactDuty:
+			holdsWhen = p.HoldsWhen
+		case parser.ExtendEventAct:
+			holdsWhen = p.HoldsWhen
+		}
+
+		for _, expression := range holdsWhen {
+			if name := l.missing(expression, holdable); name != "" {
+				l.report(phrase.Location().Pos, "unreachable-rule", "Holds when rule of %s can never hold, as %s is never created or derived", declaredName(phrase), name)
+			}
+		}
+	}
+}
+
+// finite reports whether a fact has a finite domain, in the same way as the
+// interpreter does when it iterates over a fact.
+func (l *linter) finite(name string, seen map[string]bool) bool {
+	if seen[name] {
+		return false
+	}
+	seen[name] = true
+
+	fact, ok := l.phrases[name].(parser.Fact)
+	if !ok {
+		return name == "bool"
+	}
+
+	if len(fact.IdentifiedBy) > 0 {
+		for _, param := range fact.IdentifiedBy {
+			if !l.finite(l.resolve(param), seen) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return len(fact.Range) > 0 || fact.Type == "Bool"
+}
+
+// infiniteDomains reports iterators over facts without a finite domain,
+// which only range over the instances that are known when they are run.
+func (l *linter) infiniteDomains() {
+	for _, phrase := range l.input.Phrases {
+		for _, expression := range expressions(phrase) {
+			l.iterators(expression, func(iterator parser.Iterator) {
+				keyword := strings.ReplaceAll(iterator.Iterator[:1]+strings.ToLower(iterator.Iterator[1:]), "-", " ")
+
+				for _, bind := range iterator.Binds {
+					name := l.resolve(bind)
+					if _, ok := l.phrases[name].(parser.Fact); !ok && !builtinFacts[name] {
+						continue
+					}
+
+					if !l.finite(name, make(map[string]bool)) {
+						l.report(phrase.Location().Pos, "infinite-domain", "%s over %s only ranges over its known instances, as %s has no finite domain", keyword, bind, name)
+					}
+				}
+			})
+		}
+	}
+}
+
+// iterators calls f for every iterator in an expression.
+func (l *linter) iterators(expression parser.Expression, f func(parser.Iterator)) {
+	switch e := expression.(type) {
+	case parser.ConstructorApplication:
+		for _, operand := range e.Operands {
+			l.iterators(operand, f)
+		}
+	case parser.Operator:
+		if e.Left != nil {
+			l.iterators(e.Left, f)
+		}
+		if e.Right != nil {
+			l.iterators(e.Right, f)
+		}
+	case parser.Iterator:
+		f(e)
+		l.iterators(e.Expression, f)
+	case parser.Projection:
+		l.iterators(e.Operand, f)
+	}
+}
@@ -0,0 +1,262 @@
+// Package logging writes leveled, structured log lines, either in logfmt or
+// as JSON objects. Every line has a time, a level and a message, followed by
+// the fields of the logger and of the call, as alternating keys and values:
+//
+//	logger := logging.New(os.Stderr, logging.LevelInfo, logging.FormatLogfmt)
+//	logger.With("request", id).Warn("fact not found", "fact", name)
+//
+// writes
+//
+//	time=2023-05-01T12:00:00.000Z level=warn msg="fact not found" request=4f2a fact=citizen
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Level is the severity of a log line. Lines below the level of a logger are
+// left out.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return "level(" + strconv.Itoa(int(l)) + ")"
+	}
+
+	return levelNames[l]
+}
+
+// ParseLevel returns the level with the given name: debug, info, warn or
+// error.
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown log level %q, expected one of %s", name, strings.Join(levelNames, ", "))
+}
+
+// Format is the way that log lines are written.
+type Format int
+
+const (
+	// FormatLogfmt writes lines of key=value pairs.
+	FormatLogfmt Format = iota
+	// FormatJSON writes a JSON object per line.
+	FormatJSON
+)
+
+var formatNames = []string{"logfmt", "json"}
+
+func (f Format) String() string {
+	if f < FormatLogfmt || f > FormatJSON {
+		return "format(" + strconv.Itoa(int(f)) + ")"
+	}
+
+	return formatNames[f]
+}
+
+// ParseFormat returns the format with the given name: logfmt or json.
+func ParseFormat(name string) (Format, error) {
+	for i, formatName := range formatNames {
+		if strings.EqualFold(name, formatName) {
+			return Format(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown log format %q, expected one of %s", name, strings.Join(formatNames, ", "))
+}
+
+// Logger writes log lines of at least its level. A logger and the loggers
+// derived from it with With share a lock, so that their lines are never
+// interleaved, and can be used from several goroutines.
+type Logger struct {
+	out    io.Writer
+	lock   *sync.Mutex
+	level  Level
+	format Format
+	fields []interface{}
+}
+
+// New returns a logger that writes lines of at least the given level to out.
+func New(out io.Writer, level Level, format Format) *Logger {
+	return &Logger{out: out, lock: &sync.Mutex{}, level: level, format: format}
+}
+
+// Discard returns a logger that writes nothing.
+func Discard() *Logger {
+	return New(io.Discard, LevelError+1, FormatLogfmt)
+}
+
+// With returns a logger that adds the given keys and values to every line.
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	fields := make([]interface{}, 0, len(l.fields)+len(keyvals))
+	fields = append(fields, l.fields...)
+	fields = append(fields, keyvals...)
+
+	return &Logger{out: l.out, lock: l.lock, level: l.level, format: l.format, fields: fields}
+}
+
+// Enabled reports whether lines of the given level are written, so that
+// expensive values are only computed when they are needed.
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.level
+}
+
+func (l *Logger) Debug(msg string, keyvals ...interface{}) {
+	l.Log(LevelDebug, msg, keyvals...)
+}
+
+func (l *Logger) Info(msg string, keyvals ...interface{}) {
+	l.Log(LevelInfo, msg, keyvals...)
+}
+
+func (l *Logger) Warn(msg string, keyvals ...interface{}) {
+	l.Log(LevelWarn, msg, keyvals...)
+}
+
+func (l *Logger) Error(msg string, keyvals ...interface{}) {
+	l.Log(LevelError, msg, keyvals...)
+}
+
+// Log writes a line of the given level. A key without a value gets the
+// value "MISSING".
+func (l *Logger) Log(level Level, msg string, keyvals ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	fields := make([]interface{}, 0, 6+len(l.fields)+len(keyvals))
+	fields = append(fields, "time", time.Now().UTC().Format("2006-01-02T15:04:05.000Z07:00"), "level", level.String(), "msg", msg)
+	fields = append(fields, l.fields...)
+	fields = append(fields, keyvals...)
+	if len(fields)%2 != 0 {
+		fields = append(fields, "MISSING")
+	}
+
+	var line bytes.Buffer
+	if l.format == FormatJSON {
+		writeJSON(&line, fields)
+	} else {
+		writeLogfmt(&line, fields)
+	}
+	line.WriteByte('\n')
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.out.Write(line.Bytes())
+}
+
+func writeJSON(line *bytes.Buffer, fields []interface{}) {
+	line.WriteByte('{')
+
+	for i := 0; i < len(fields); i += 2 {
+		if i > 0 {
+			line.WriteByte(',')
+		}
+
+		key, _ := json.Marshal(fmt.Sprint(fields[i]))
+		line.Write(key)
+		line.WriteByte(':')
+
+		value, err := json.Marshal(jsonValue(fields[i+1]))
+		if err != nil {
+			value, _ = json.Marshal(fmt.Sprint(fields[i+1]))
+		}
+		line.Write(value)
+	}
+
+	line.WriteByte('}')
+}
+
+// jsonValue returns the value as it is written in JSON lines. Errors and
+// values with a String method are written as their text, everything else is
+// encoded as it is.
+func jsonValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case error:
+		return value.Error()
+	case fmt.Stringer:
+		return value.String()
+	default:
+		return value
+	}
+}
+
+func writeLogfmt(line *bytes.Buffer, fields []interface{}) {
+	for i := 0; i < len(fields); i += 2 {
+		if i > 0 {
+			line.WriteByte(' ')
+		}
+
+		line.WriteString(logfmtKey(fmt.Sprint(fields[i])))
+		line.WriteByte('=')
+		line.WriteString(logfmtValue(fields[i+1]))
+	}
+}
+
+// logfmtValue returns the value as it is written in logfmt lines. Values
+// that are not text, numbers or booleans are encoded as JSON, and quoted
+// when needed.
+func logfmtValue(value interface{}) string {
+	var text string
+
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		text = value
+	case error:
+		text = value.Error()
+	case fmt.Stringer:
+		text = value.String()
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(value)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			text = fmt.Sprint(value)
+		} else {
+			text = string(data)
+		}
+	}
+
+	if text == "" || strings.IndexFunc(text, needsQuotes) >= 0 {
+		return strconv.Quote(text)
+	}
+
+	return text
+}
+
+func logfmtKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if needsQuotes(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+func needsQuotes(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == unicode.ReplacementChar || !unicode.IsPrint(r)
+}
@@ -0,0 +1,94 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Generator generates schemas from Go types, using the json tags of their
+// fields. Types that are given a name with Name are referred to instead of
+// repeated.
+type Generator struct {
+	names map[reflect.Type]string
+}
+
+func NewGenerator() *Generator {
+	return &Generator{names: make(map[reflect.Type]string)}
+}
+
+// Name makes the generator refer to the type of v as the schema with the
+// given name.
+func (g *Generator) Name(v interface{}, name string) {
+	g.names[reflect.TypeOf(v)] = name
+}
+
+// Object generates the schema of a struct. Its fields are required unless
+// they are omitted when empty, and other fields are not allowed.
+func (g *Generator) Object(v interface{}) *Schema {
+	return g.object(reflect.TypeOf(v))
+}
+
+func (g *Generator) object(t reflect.Type) *Schema {
+	additional := false
+	schema := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: &additional,
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = g.schema(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+func (g *Generator) schema(t reflect.Type) *Schema {
+	if name, ok := g.names[t]; ok {
+		return Ref(name)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schema(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		return g.object(t)
+	case reflect.Interface:
+		// Any value
+		return &Schema{}
+	default:
+		panic(fmt.Sprintf("openapi: no schema for %s", t))
+	}
+}
@@ -0,0 +1,280 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// The reasons that a value does not match its schema.
+const (
+	ReasonRequired      = "required"
+	ReasonUnknownField  = "unknown_field"
+	ReasonType          = "type"
+	ReasonEnum          = "enum"
+	ReasonMinimum       = "minimum"
+	ReasonItems         = "items"
+	ReasonDiscriminator = "discriminator"
+)
+
+// FieldError is a value that does not match its schema. Pointer is the JSON
+// pointer to the value, or to where a missing field should be, and Schema the
+// name of the innermost named schema that the value is checked against.
+type FieldError struct {
+	Pointer string
+	Message string
+	Reason  string
+	Schema  string
+}
+
+func fieldError(pointer string, schema string, reason string, format string, args ...interface{}) FieldError {
+	return FieldError{Pointer: pointer, Message: fmt.Sprintf(format, args...), Reason: reason, Schema: schema}
+}
+
+func (e FieldError) Error() string {
+	if e.Pointer == "" {
+		return e.Message
+	}
+
+	return e.Pointer + ": " + e.Message
+}
+
+// Validate checks a JSON value against a schema of the document. The value
+// must be decoded with UseNumber, so that integers can be told apart.
+func (d *Document) Validate(schema *Schema, value interface{}) []FieldError {
+	return d.validate(schema, value, "", "")
+}
+
+func (d *Document) validate(schema *Schema, value interface{}, pointer string, name string) []FieldError {
+	for schema != nil && schema.Ref != "" {
+		name = strings.TrimPrefix(schema.Ref, refPrefix)
+		schema = d.Components.Schemas[name]
+	}
+	if schema == nil {
+		return nil
+	}
+
+	if value == nil && schema.Nullable {
+		return nil
+	}
+
+	if schema.Discriminator != nil {
+		return d.validateDiscriminated(schema, value, pointer, name)
+	}
+
+	if len(schema.OneOf) > 0 {
+		return d.validateOneOf(schema, value, pointer, name)
+	}
+
+	if schema.Type != "" && !hasType(value, schema.Type) {
+		return []FieldError{fieldError(pointer, name, ReasonType, "expected %s, got %s", article(schema.Type), article(typeOf(value)))}
+	}
+
+	switch value := value.(type) {
+	case string:
+		if len(schema.Enum) > 0 && !contains(schema.Enum, value) {
+			return []FieldError{fieldError(pointer, name, ReasonEnum, "unknown value %q, expected one of %s", value, strings.Join(schema.Enum, ", "))}
+		}
+	case json.Number:
+		if number, err := value.Float64(); err == nil && schema.Minimum != nil && number < *schema.Minimum {
+			return []FieldError{fieldError(pointer, name, ReasonMinimum, "expected at least %v, got %v", *schema.Minimum, value)}
+		}
+	case []interface{}:
+		return d.validateArray(schema, value, pointer, name)
+	case map[string]interface{}:
+		return d.validateObject(schema, value, pointer, name)
+	}
+
+	return nil
+}
+
+func (d *Document) validateArray(schema *Schema, value []interface{}, pointer string, name string) []FieldError {
+	if schema.MinItems != nil && len(value) < *schema.MinItems {
+		return []FieldError{fieldError(pointer, name, ReasonItems, "expected at least %s, got %d", items(*schema.MinItems), len(value))}
+	}
+	if schema.MaxItems != nil && len(value) > *schema.MaxItems {
+		return []FieldError{fieldError(pointer, name, ReasonItems, "expected at most %s, got %d", items(*schema.MaxItems), len(value))}
+	}
+
+	var errs []FieldError
+	for i, item := range value {
+		errs = append(errs, d.validate(schema.Items, item, fmt.Sprintf("%s/%d", pointer, i), name)...)
+	}
+
+	return errs
+}
+
+func (d *Document) validateObject(schema *Schema, value map[string]interface{}, pointer string, name string) []FieldError {
+	var errs []FieldError
+
+	for _, field := range schema.Required {
+		if _, ok := value[field]; !ok {
+			errs = append(errs, fieldError(pointer+"/"+escape(field), name, ReasonRequired, "missing required field"))
+		}
+	}
+
+	fields := make([]string, 0, len(value))
+	for field := range value {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		property, ok := schema.Properties[field]
+		if !ok {
+			if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+				errs = append(errs, fieldError(pointer+"/"+escape(field), name, ReasonUnknownField, "unknown field"))
+			}
+			continue
+		}
+
+		errs = append(errs, d.validate(property, value[field], pointer+"/"+escape(field), name)...)
+	}
+
+	return errs
+}
+
+// validateDiscriminated validates an object against the schema that the
+// value of its discriminator property maps to.
+func (d *Document) validateDiscriminated(schema *Schema, value interface{}, pointer string, name string) []FieldError {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return []FieldError{fieldError(pointer, name, ReasonType, "expected an object, got %s", article(typeOf(value)))}
+	}
+
+	field := pointer + "/" + escape(schema.Discriminator.PropertyName)
+	property, ok := object[schema.Discriminator.PropertyName]
+	if !ok {
+		return []FieldError{fieldError(field, name, ReasonRequired, "missing required field")}
+	}
+
+	values := make([]string, 0, len(schema.Discriminator.Mapping))
+	for value := range schema.Discriminator.Mapping {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	discriminator, _ := property.(string)
+	ref, ok := schema.Discriminator.Mapping[discriminator]
+	if !ok {
+		return []FieldError{fieldError(field, name, ReasonDiscriminator, "unknown value %v, expected one of %s", quote(property), strings.Join(values, ", "))}
+	}
+
+	return d.validate(&Schema{Ref: ref}, value, pointer, name)
+}
+
+// validateOneOf validates a value that must match one of the schemas. If it
+// matches none, the errors are those of the closest schema: the one of the
+// right type with the fewest errors.
+func (d *Document) validateOneOf(schema *Schema, value interface{}, pointer string, name string) []FieldError {
+	var closest []FieldError
+	types := make([]string, 0, len(schema.OneOf))
+
+	for _, alternative := range schema.OneOf {
+		resolved := d.resolve(alternative)
+		if resolved.Type != "" && !contains(types, resolved.Type) {
+			types = append(types, resolved.Type)
+		}
+		if resolved.Type != "" && !hasType(value, resolved.Type) {
+			continue
+		}
+
+		errs := d.validate(alternative, value, pointer, name)
+		if len(errs) == 0 {
+			return nil
+		}
+		if closest == nil || len(errs) < len(closest) {
+			closest = errs
+		}
+	}
+
+	if closest == nil {
+		return []FieldError{fieldError(pointer, name, ReasonType, "expected one of %s, got %s", strings.Join(types, ", "), article(typeOf(value)))}
+	}
+
+	return closest
+}
+
+func hasType(value interface{}, typ string) bool {
+	switch typ {
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		// Like JSON Schema, numbers without a fractional part are integers
+		if _, err := number.Int64(); err == nil {
+			return true
+		}
+		rational, ok := new(big.Rat).SetString(number.String())
+		return ok && rational.IsInt() && rational.Num().IsInt64()
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	default:
+		return typeOf(value) == typ
+	}
+}
+
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func article(typ string) string {
+	switch typ {
+	case "null":
+		return typ
+	case "array", "integer", "object":
+		return "an " + typ
+	default:
+		return "a " + typ
+	}
+}
+
+func items(n int) string {
+	if n == 1 {
+		return "1 item"
+	}
+
+	return fmt.Sprintf("%d items", n)
+}
+
+func quote(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// escape escapes a property name for use in a JSON pointer.
+func escape(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+func contains(list []string, item string) bool {
+	for _, element := range list {
+		if element == item {
+			return true
+		}
+	}
+
+	return false
+}
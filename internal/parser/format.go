@@ -0,0 +1,406 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// Indentation of the clauses of a declaration, and of the lines that
+// continue an expression.
+const (
+	clauseIndent       = "  "
+	continuationIndent = "    "
+)
+
+// clauseKeywords start a clause on a new line when formatting.
+var clauseKeywords = []string{
+	"DerivedFrom", "HoldsWhen", "ConditionedBy", "ViolatedWhen",
+	"SyncsWith", "Creates", "Terminates", "Obfuscates",
+}
+
+// formatLine is a line of formatted output.
+type formatLine struct {
+	indent  string
+	code    string
+	comment string
+	// fact is set for the first line of a Fact declaration, and align is
+	// the position of its Identified by in code, or -1
+	fact  bool
+	align int
+	// other is set for the first line of any other phrase
+	other bool
+	blank bool
+}
+
+// formatter formats the tokens of a single file. Phrases are recognised by
+// parsing the file, but the output is built from the tokens themselves, so
+// that everything but whitespace is kept as written.
+type formatter struct {
+	symbols map[string]lexer.TokenType
+	lines   []formatLine
+	// line is the line that is being built
+	line *formatLine
+	// last is the index of the last line with code of the current phrase
+	last int
+	prev lexer.Token
+	// beforePrev is the token before prev
+	beforePrev lexer.Token
+	// head is set while the first line of a phrase is built, and clause
+	// while a clause is built
+	head   bool
+	clause bool
+	// first is the first token of the current phrase
+	first lexer.Token
+	// pending are comments on their own line that are not placed yet
+	pending []formatLine
+}
+
+// Format formats an eFLINT program. Clauses such as Holds when and Creates
+// are put on separate lines, every phrase ends with a dot, the Identified by
+// of consecutive facts is aligned, and so are comments at the end of
+// consecutive lines. Comments and included files are kept as they are.
+func Format(filename string, src []byte) ([]byte, error) {
+	ini, err := parser.ParseBytes(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	lex, err := eflintLexer.Lex(filename, bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := lexer.ConsumeAll(lex)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &formatter{
+		symbols: eflintLexer.Symbols(),
+		lines:   make([]formatLine, 0),
+		last:    -1,
+		pending: make([]formatLine, 0),
+	}
+
+	// The phrase that every token belongs to, if any. Tokens outside of
+	// phrases are the dots that end them.
+	spans := make([]Span, 0, len(ini.Phrases))
+	for _, phrase := range ini.Phrases {
+		spans = append(spans, phrase.Location())
+	}
+
+	current := -1
+	index := 0
+
+	for _, token := range tokens {
+		switch {
+		case token.EOF():
+			continue
+		case f.is(token, "Comment", "HashComment"):
+			f.comment(token, current >= 0)
+			continue
+		}
+
+		for index < len(spans) && token.Pos.Offset >= spans[index].EndPos.Offset {
+			index++
+		}
+
+		phrase := -1
+		if index < len(spans) && token.Pos.Offset >= spans[index].Pos.Offset {
+			phrase = index
+		}
+
+		switch {
+		case phrase >= 0 && phrase != current:
+			f.end(current >= 0)
+			f.comments("")
+			f.begin(token)
+			current = phrase
+		case phrase >= 0:
+			switch {
+			case f.is(token, clauseKeywords...):
+				f.comments(clauseIndent)
+			case f.clause:
+				f.comments(continuationIndent)
+			default:
+				f.comments(clauseIndent)
+			}
+			f.token(token)
+		case f.is(token, "Dot"):
+			// Repeated dots are left out
+			f.end(current >= 0)
+			f.comments("")
+			current = -1
+		}
+
+		f.beforePrev = f.prev
+		f.prev = token
+	}
+
+	f.end(current >= 0)
+	f.flush()
+	f.comments("")
+
+	return f.output(), nil
+}
+
+func (f *formatter) is(token lexer.Token, types ...string) bool {
+	for _, t := range types {
+		if token.Type == f.symbols[t] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// flush adds the line that is being built to the output.
+func (f *formatter) flush() {
+	if f.line == nil {
+		return
+	}
+
+	f.lines = append(f.lines, *f.line)
+	if f.line.code != "" {
+		f.last = len(f.lines) - 1
+	}
+
+	f.line = nil
+}
+
+func (f *formatter) newLine(indent string, blank bool) {
+	f.flush()
+	f.line = &formatLine{indent: indent, align: -1, blank: blank && len(f.lines) > 0}
+}
+
+// blankBefore reports whether there is an empty line between the previous
+// token and the given one.
+func (f *formatter) blankBefore(token lexer.Token) bool {
+	return f.prev.Pos.Line > 0 && token.Pos.Line-f.prev.Pos.Line > 1
+}
+
+// begin starts a new phrase with the given token.
+func (f *formatter) begin(token lexer.Token) {
+	f.newLine("", f.blankBefore(token))
+	f.line.code = token.Value
+	f.line.fact = f.is(token, "Fact")
+	f.line.other = !f.line.fact
+	f.head = true
+	f.clause = false
+	f.first = token
+}
+
+// end ends the current phrase with a dot.
+func (f *formatter) end(open bool) {
+	if !open {
+		return
+	}
+
+	if f.line != nil && f.line.code != "" {
+		f.line.code += "."
+	} else if f.last >= 0 {
+		f.lines[f.last].code += "."
+	}
+
+	if f.line != nil && f.line.comment != "" {
+		f.flush()
+	}
+
+	f.head = false
+	f.clause = false
+}
+
+// token adds a token of the current phrase.
+func (f *formatter) token(token lexer.Token) {
+	switch {
+	case f.is(token, clauseKeywords...):
+		f.newLine(clauseIndent, false)
+		f.line.code = token.Value
+		f.head = false
+		f.clause = true
+		return
+	case f.line == nil || token.Pos.Line > f.prev.Pos.Line && !f.is(f.prev, clauseKeywords...):
+		// Line breaks within expressions are kept
+		indent := clauseIndent
+		if f.clause {
+			indent = continuationIndent
+		}
+		f.newLine(indent, false)
+		f.line.code = token.Value
+		f.head = false
+		return
+	}
+
+	if f.head && f.line.fact && f.is(token, "IdentifiedBy") {
+		f.line.align = len(f.line.code) + 1
+	}
+
+	if f.space(token) {
+		f.line.code += " "
+	}
+
+	f.line.code += token.Value
+}
+
+// space reports whether a space goes between the previous token and the
+// given one, which are on the same line.
+func (f *formatter) space(token lexer.Token) bool {
+	prev := f.prev
+
+	switch {
+	case f.is(token, "Comma", "RParen", "Dot"):
+		return false
+	case f.is(prev, "LParen", "Dot", "Neg"):
+		return false
+	case prev == f.first && f.is(prev, "Create", "Terminate", "Obfuscate", "Bquery", "Iquery", "IqueryHolds"):
+		// Keep a minus sign from being read as part of the prefix
+		return f.is(token, "Terminate")
+	case f.is(prev, "Terminate") && !f.operand(f.beforePrev):
+		return false
+	case f.is(token, "LParen"):
+		return !f.is(prev, "FactID", "DecoratedFactID", "BracketedFactID", "Holds", "Enabled", "Count", "CountDistinct", "Sum", "Max", "Min", "Avg", "Not")
+	}
+
+	return true
+}
+
+// operand reports whether a token can end an operand, which tells a binary
+// minus from a unary one.
+func (f *formatter) operand(token lexer.Token) bool {
+	return f.is(token, "FactID", "DecoratedFactID", "BracketedFactID", "String", "QuotedString", "Int", "True", "False", "RParen")
+}
+
+// comment adds a comment at the end of the line that is being built, or on a
+// line of its own. Comments on their own line within a phrase are placed once
+// the next token shows whether they belong to the phrase or follow it.
+func (f *formatter) comment(token lexer.Token, open bool) {
+	if f.line != nil && f.line.code != "" && f.line.comment == "" && token.Pos.Line == f.prev.Pos.Line {
+		f.line.comment = token.Value
+		f.flush()
+		return
+	}
+
+	// A comment after the last phrase on its line
+	if f.line == nil && f.last >= 0 && f.last == len(f.lines)-1 && f.lines[f.last].comment == "" && token.Pos.Line == f.prev.Pos.Line {
+		f.lines[f.last].comment = token.Value
+		return
+	}
+
+	f.flush()
+	f.pending = append(f.pending, formatLine{comment: token.Value, align: -1, blank: f.blankBefore(token)})
+	f.prev = token
+
+	if !open {
+		f.comments("")
+	}
+}
+
+// comments adds the pending comments with the given indentation.
+func (f *formatter) comments(indent string) {
+	for _, line := range f.pending {
+		line.indent = indent
+		line.blank = line.blank && len(f.lines) > 0
+		f.lines = append(f.lines, line)
+	}
+
+	f.pending = f.pending[:0]
+}
+
+// output aligns the lines and joins them.
+func (f *formatter) output() []byte {
+	f.alignFacts()
+	f.alignComments()
+
+	var b strings.Builder
+
+	for _, line := range f.lines {
+		if line.blank {
+			b.WriteString("\n")
+		}
+
+		text := line.indent + line.code
+		if line.comment != "" {
+			if line.code != "" {
+				text += " "
+			}
+			text += line.comment
+		}
+
+		b.WriteString(strings.TrimRight(text, " "))
+		b.WriteString("\n")
+	}
+
+	return []byte(b.String())
+}
+
+// alignFacts aligns the Identified by of consecutive fact declarations. A
+// block of facts ends at an empty line, a comment on its own line or another
+// kind of phrase.
+func (f *formatter) alignFacts() {
+	block := make([]int, 0)
+
+	align := func() {
+		width := 0
+		for _, i := range block {
+			if f.lines[i].align > width {
+				width = f.lines[i].align
+			}
+		}
+
+		for _, i := range block {
+			if line := &f.lines[i]; line.align >= 0 && line.align < width {
+				padding := strings.Repeat(" ", width-line.align)
+				line.code = line.code[:line.align] + padding + line.code[line.align:]
+			}
+		}
+
+		block = block[:0]
+	}
+
+	for i, line := range f.lines {
+		if line.blank || line.other || line.code == "" {
+			align()
+		}
+
+		if line.fact {
+			block = append(block, i)
+		}
+	}
+
+	align()
+}
+
+// alignComments aligns comments at the end of consecutive lines.
+func (f *formatter) alignComments() {
+	block := make([]int, 0)
+
+	align := func() {
+		width := 0
+		for _, i := range block {
+			if n := len(f.lines[i].indent + f.lines[i].code); n > width {
+				width = n
+			}
+		}
+
+		for _, i := range block {
+			line := &f.lines[i]
+			line.code += strings.Repeat(" ", width-len(line.indent+line.code))
+		}
+
+		block = block[:0]
+	}
+
+	for i, line := range f.lines {
+		if line.blank || line.code == "" || line.comment == "" {
+			align()
+		}
+
+		if line.code != "" && line.comment != "" {
+			block = append(block, i)
+		}
+	}
+
+	align()
+}
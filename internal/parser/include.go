@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// includer expands #include and #require directives, which are the same: both
+// include a file only if it has not been included before, so that files can
+// share vocabularies. Paths are quoted, and relative to the directory of the
+// file that contains the directive.
+type includer struct {
+	loaded map[string]bool
+	stack  []includedFile
+}
+
+// includedFile is a file that is being parsed, identified by its absolute
+// path, and reported by the name it was included with.
+type includedFile struct {
+	path string
+	name string
+}
+
+func newIncluder() *includer {
+	return &includer{
+		loaded: make(map[string]bool),
+		stack:  make([]includedFile, 0),
+	}
+}
+
+// parse parses a single file and replaces its directives by the phrases of
+// the files they refer to.
+func (inc *includer) parse(filename string, r io.Reader) (*Input, error) {
+	if filename != "" {
+		path, err := filepath.Abs(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		inc.loaded[path] = true
+		inc.stack = append(inc.stack, includedFile{path: path, name: filename})
+		defer func() { inc.stack = inc.stack[:len(inc.stack)-1] }()
+	}
+
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	ini, err := parser.ParseBytes(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	phrases := make([]Phrase, 0, len(ini.Phrases))
+
+	for _, phrase := range ini.Phrases {
+		directive, ok := phrase.(Include)
+		if !ok {
+			phrases = append(phrases, phrase)
+			continue
+		}
+
+		// Quoted strings cannot be told apart from unquoted ones after
+		// lexing, so the path is checked in the source
+		path := bytes.TrimLeft(src[directive.Pos.Offset+len(directive.Kind):], " \t\r\n")
+		if !bytes.HasPrefix(path, []byte(`"`)) {
+			return nil, fmt.Errorf("%s: expected a quoted path after %s", directive.Pos, directive.Kind)
+		}
+
+		included, err := inc.include(filename, directive)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", directive.Pos, err)
+		}
+
+		phrases = append(phrases, included...)
+	}
+
+	ini.Phrases = phrases
+
+	return ini, nil
+}
+
+// include returns the phrases of the file that the directive refers to.
+func (inc *includer) include(filename string, directive Include) ([]Phrase, error) {
+	path := directive.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(filename), path)
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, parent := range inc.stack {
+		if parent.path == path {
+			cycle := make([]string, 0)
+			for _, file := range inc.stack[i:] {
+				cycle = append(cycle, file.name)
+			}
+			cycle = append(cycle, parent.name)
+
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	if inc.loaded[path] {
+		return []Phrase{}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot include %q: %w", directive.Path, err)
+	}
+	defer file.Close()
+
+	// Keep the path as it was written relative to the including file, so
+	// that positions in the included file are as readable as possible
+	name := filepath.Join(filepath.Dir(filename), directive.Path)
+	if filepath.IsAbs(directive.Path) {
+		name = directive.Path
+	}
+
+	ini, err := inc.parse(name, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return ini.Phrases, nil
+}
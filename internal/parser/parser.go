@@ -3,8 +3,10 @@ package parser
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
 	"github.com/alecthomas/participle/v2"
 	"github.com/alecthomas/participle/v2/lexer"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -14,54 +16,63 @@ var (
 	eflintLexer = lexer.MustSimple([]lexer.SimpleRule{
 		{"whitespace", `\s+`},
 		{"Comment", `//.*`},
-		// TODO: Add support for quotation marks.
-		{`DecoratedFactID`, `[a-z][a-z_-]*[0-9]+`},
-		{`FactID`, `[a-z][a-z_-]*`},
-		{`Fact`, `Fact`},
-		{`StringType`, `String`},
-		{`IntType`, `Int`},
-		{`True`, `True`},
-		{`False`, `False`},
-
-		{`IdentifiedBy`, `Identified by`},
-		{`DerivedFrom`, `Derived from`},
-		{`HoldsWhen`, `Holds when`},
-		{`ConditionedBy`, `Conditioned by`},
-		{`ViolatedWhen`, `Violated when`},
-		{`Placeholder`, `Placeholder`},
-		{`Predicate`, `Predicate`},
-		{`Invariant`, `Invariant`},
-		{`Event`, `Event`},
-		{`Duty`, `Duty`},
-		{`RelatedTo`, `Related to`},
-		{`SyncsWith`, `Syncs with`},
-		{`Creates`, `Creates`},
-		{`Holds`, `Holds`},
-		{`Enabled`, `Enabled`},
-		{`Terminates`, `Terminates`},
-		{`Obfuscates`, `Obfuscates`},
-		{`Actor`, `Actor`},
-		{`Act`, `Act`},
-		{`Recipient`, `Recipient`},
-		{`Extend`, `Extend`},
-		{`Holder`, `Holder`},
-		{`Claimant`, `Claimant`},
-
-		{`Foreach`, `Foreach`},
-		{`Forall`, `Forall`},
-		{`For`, `For`},
-		{`When`, `When`},
-
-		// Iterators
-		{`Count`, `Count`},
-		{`Sum`, `Sum`},
-		{`Max`, `Max`},
-		{`Min`, `Min`},
-
-		{`Not`, `Not`},
-
-		{`True`, `True`},
-		{`False`, `False`},
+		{`Include`, `#include\b`},
+		{`Require`, `#require\b`},
+		{`QuotedString`, `"(\\.|[^"\\\n])*"`},
+		{`BracketedFactID`, `\[[^\]\n]+\]`},
+		// Identifiers that end with digits or primes are decorated variables,
+		// see decorateIdentifier.
+		{`FactID`, `[a-z][a-zA-Z0-9_-]*'*`},
+		{`DecoratedFactID`, `[a-z][a-zA-Z0-9_-]*['0-9]`},
+		{`Fact`, `Fact\b`},
+		{`StringType`, `String\b`},
+		{`IntType`, `Int\b`},
+		{`BoolType`, `Bool\b`},
+		{`True`, `True\b`},
+		{`False`, `False\b`},
+
+		{`IdentifiedBy`, `Identified by\b`},
+		{`DerivedFrom`, `Derived from\b`},
+		{`HoldsWhen`, `Holds when\b`},
+		{`ConditionedBy`, `Conditioned by\b`},
+		{`ViolatedWhen`, `Violated when\b`},
+		{`Placeholder`, `Placeholder\b`},
+		{`Predicate`, `Predicate\b`},
+		{`Invariant`, `Invariant\b`},
+		{`Event`, `Event\b`},
+		{`Duty`, `Duty\b`},
+		{`RelatedTo`, `Related to\b`},
+		{`SyncsWith`, `Syncs with\b`},
+		{`Creates`, `Creates\b`},
+		{`Holds`, `Holds\b`},
+		{`Enabled`, `Enabled\b`},
+		{`Terminates`, `Terminates\b`},
+		{`Obfuscates`, `Obfuscates\b`},
+		{`Actor`, `Actor\b`},
+		{`Act`, `Act\b`},
+		{`Recipient`, `Recipient\b`},
+		{`Extend`, `Extend\b`},
+		{`Holder`, `Holder\b`},
+		{`Claimant`, `Claimant\b`},
+
+		{`Foreach`, `Foreach\b`},
+		{`Exists`, `Exists\b`},
+		{`Forall`, `Forall\b`},
+		{`For`, `For\b`},
+		{`When`, `When\b`},
+
+		// Aggregates
+		{`CountDistinct`, `Count distinct\b`},
+		{`Count`, `Count\b`},
+		{`Sum`, `Sum\b`},
+		{`Max`, `Max\b`},
+		{`Min`, `Min\b`},
+		{`Avg`, `Avg\b`},
+
+		{`Not`, `Not\b`},
+
+		{`True`, `True\b`},
+		{`False`, `False\b`},
 		{`OR`, `\|\|`},
 		{`AND`, `&&`},
 		{`EQ`, `==`},
@@ -70,11 +81,11 @@ var (
 		{`LTE`, `<=`},
 		{`GT`, `>`},
 		{`LT`, `<`},
-		{`NOT`, `NOT`},
+		{`NOT`, `NOT\b`},
 		{`Neg`, `!`},
 
 		{`Int`, `[0-9]+`},
-		{`String`, `[A-Z][a-z0-9]*`},
+		{`String`, `[A-Z][a-zA-Z0-9_]*`},
 
 		// Statements
 		{`IqueryHolds`, `\?--`},
@@ -87,75 +98,102 @@ var (
 		{`Comma`, `,`},
 		{`Star`, `\*`},
 		{`Dot`, `\.`},
-		{`Quote`, `\'`},
 		{`Div`, `/`},
 		{`Mod`, `%`},
 		{`LParen`, `\(`},
 		{`RParen`, `\)`},
 		{`Colon`, `:`},
-		{"comment", `[#;][^\n]*`},
+		// Comments can also start with # or ;, these are kept as tokens so
+		// that the formatter can preserve them.
+		{"HashComment", `[#;][^\n]*`},
 		{"Newline", `\n`},
 	})
-	parser = participle.MustBuild[Input](
-		participle.Lexer(eflintLexer),
-		participle.Union[Phrase](Fact{}, Query{}, Statement{}, Placeholder{}, Predicate{}, Event{}, Act{}, Duty{}, ExtendFactDuty{}, ExtendEventAct{}),
+	parser = participle.MustBuild[Input](append(options,
+		participle.Union[Phrase](Include{}, Fact{}, Query{}, Statement{}, Placeholder{}, Predicate{}, Event{}, Act{}, Duty{}, ExtendFactDuty{}, ExtendEventAct{}),
 		participle.Union[Range](String{}, Int{}),
+	)...)
+	expressionListParser = participle.MustBuild[expressionList](options...)
+	statementListParser  = participle.MustBuild[statementList](options...)
+	options              = []participle.Option{
+		participle.Lexer(eflintLexer),
 		participle.ParseTypeWith[Expression](parseExpression),
-		participle.Elide("Comment"),
-	)
+		participle.Map(unquoteString, "QuotedString"),
+		participle.Map(unbracketIdentifier, "BracketedFactID"),
+		participle.Map(decorateIdentifier, "FactID"),
+		participle.Elide("Comment", "HashComment"),
+	}
 	version = "0.1.0"
 	kind    = "phrases"
 	updates = true
 
-	precedences = map[string]precedence{
-		"||": {1, 1},
-		"&&": {1, 1},
-		"==": {2, 2},
-		"!=": {2, 2},
-		"+":  {3, 3},
-		"-":  {3, 3},
-		"*":  {5, 4},
-		"/":  {7, 6},
-		"%":  {9, 8},
-		"<":  {10, 10},
-		">":  {10, 10},
-		"<=": {10, 10},
-		">=": {10, 10},
+	// operatorNames maps the operators to their names in the JSON
+	// specification. The binary operators are added from
+	// eflint.BinaryOperators, which also holds their precedence.
+	operatorNames = withBinaryOperators(map[string]string{
+		"!": "NOT",
+
+		"WHEN": "WHEN",
+
+		"HOLDS":   "HOLDS",
+		"ENABLED": "ENABLED",
+		"NOT":     "NOT",
+	})
+)
+
+// unquoteString turns a quoted string into a String token with the unescaped
+// value, so that it can be used anywhere an unquoted string can.
+func unquoteString(token lexer.Token) (lexer.Token, error) {
+	value, err := strconv.Unquote(token.Value)
+	if err != nil {
+		return token, participle.Errorf(token.Pos, "invalid string %s: %v", token.Value, err)
 	}
 
-	operatorNames = map[string]string{
-		"+": "ADD",
-		"-": "SUB",
-		"*": "MUL",
-		"/": "DIV",
-		"%": "MOD",
+	token.Type = eflintLexer.Symbols()["String"]
+	token.Value = value
 
-		"==": "EQ",
-		"!=": "NEQ",
+	return token, nil
+}
 
-		"||": "OR",
-		"&&": "AND",
+// unbracketIdentifier turns a bracketed name, such as [legal person], into a
+// regular identifier.
+func unbracketIdentifier(token lexer.Token) (lexer.Token, error) {
+	token.Type = eflintLexer.Symbols()["FactID"]
+	token.Value = strings.TrimSpace(token.Value[1 : len(token.Value)-1])
 
-		"<":  "LT",
-		">":  "GT",
-		">=": "GTE",
-		"<=": "LTE",
+	return token, nil
+}
 
-		"!": "NOT",
+// decorateIdentifier marks identifiers that end with digits or primes, such
+// as person1 or person', as decorated variables. These refer to the fact
+// without the decoration, and cannot be used as the name of a fact.
+func decorateIdentifier(token lexer.Token) (lexer.Token, error) {
+	if strings.TrimRight(token.Value, "'0123456789") != token.Value {
+		token.Type = eflintLexer.Symbols()["DecoratedFactID"]
+	}
 
-		"WHEN":  "WHEN",
-		"SUM":   "SUM",
-		"MAX":   "MAX",
-		"MIN":   "MIN",
-		"COUNT": "COUNT",
+	return token, nil
+}
 
-		"HOLDS":   "HOLDS",
-		"ENABLED": "ENABLED",
-		"NOT":     "NOT",
+// withBinaryOperators adds the binary operators to a map from operators to
+// their names in the JSON specification.
+func withBinaryOperators(names map[string]string) map[string]string {
+	for name, operator := range eflint.BinaryOperators {
+		names[operator.Symbol] = name
 	}
-)
 
-type precedence struct{ Left, Right int }
+	return names
+}
+
+// aggregates maps the aggregate tokens to the iterators of the JSON
+// specification.
+var aggregates = map[lexer.TokenType]string{
+	eflintLexer.Symbols()["Count"]:         "COUNT",
+	eflintLexer.Symbols()["CountDistinct"]: "COUNT-DISTINCT",
+	eflintLexer.Symbols()["Sum"]:           "SUM",
+	eflintLexer.Symbols()["Max"]:           "MAX",
+	eflintLexer.Symbols()["Min"]:           "MIN",
+	eflintLexer.Symbols()["Avg"]:           "AVG",
+}
 
 type Input struct {
 	Version string   `json:"version" parser:""`
@@ -164,22 +202,52 @@ type Input struct {
 	Updates bool     `json:"updates" parser:""`
 }
 
+type expressionList struct {
+	Expressions []Expression `parser:"(@@ (Comma @@)*)?"`
+}
+
+type statementList struct {
+	Statements []Statement `parser:"(@@ (Comma @@)*)?"`
+}
+
 type Phrase interface {
 	phrase()
+	Location() Span
+}
+
+// Span records where a phrase starts and ends in its source file.
+type Span struct {
+	Pos    lexer.Position `json:"-" parser:""`
+	EndPos lexer.Position `json:"-" parser:""`
 }
 
+func (s Span) Location() Span { return s }
+
 type Range interface {
 	isRange()
 }
 
+// Include is an #include or #require directive. It is replaced by the phrases
+// of the file it refers to while parsing, so it never ends up in the output.
+type Include struct {
+	Span
+
+	Kind string `json:"-" parser:"@(Include | Require)"`
+	Path string `json:"-" parser:"@String"`
+}
+
+func (i Include) phrase() {}
+
 type Fact struct {
+	Span
+
 	Kind          string        `json:"kind"                     parser:""`
 	Stateless     bool          `json:"stateless,omitempty"      parser:""`
 	Updates       bool          `json:"updates,omitempty"        parser:""`
 	Name          string        `json:"name,omitempty"           parser:"Fact @FactID"`
-	Type          string        `json:"type,omitempty"           parser:"( (IdentifiedBy @(StringType | IntType))"`
+	Type          string        `json:"type,omitempty"           parser:"( (IdentifiedBy @(StringType | IntType | BoolType))"`
 	IdentifiedBy  []string      `json:"identified-by,omitempty"  parser:"| (IdentifiedBy @(DecoratedFactID | FactID) ( Star @(DecoratedFactID | FactID) )*)"`
-	Range         []Range       `json:"range,omitempty"          parser:"| (IdentifiedBy (?= Int (Dot Dot)) @@ (Dot Dot) (?= Int) @@) | (IdentifiedBy @@ (Comma @@)*))?"`
+	Range         []Range       `json:"range,omitempty"          parser:"| (IdentifiedBy (?= Terminate? Int (Dot Dot)) @@ (Dot Dot) (?= Terminate? Int) @@) | (IdentifiedBy @@ (Comma @@)*))?"`
 	DerivedFrom   []Expression  `json:"derived-from,omitempty"   parser:"( (DerivedFrom @@ (Comma @@)*)"`
 	HoldsWhen     []Expression  `json:"holds-when,omitempty"     parser:"| (HoldsWhen @@ (Comma @@)*)"`
 	ConditionedBy []Expression  `json:"conditioned-by,omitempty" parser:"| (ConditionedBy @@ (Comma @@)*) )*"`
@@ -189,6 +257,8 @@ type Fact struct {
 func (f Fact) phrase() {}
 
 type Query struct {
+	Span
+
 	Kind      string     `json:"kind"                     parser:"@(IqueryHolds | Iquery | Bquery)"`
 	Stateless bool       `json:"stateless,omitempty"      parser:""`
 	Updates   bool       `json:"updates,omitempty"        parser:""`
@@ -199,6 +269,8 @@ type Query struct {
 func (q Query) phrase() {}
 
 type Statement struct {
+	Span
+
 	Kind    string     `json:"kind"    parser:"(@(Create | Obfuscate | Terminate))?"`
 	Operand Expression `json:"operand" parser:"@@"`
 }
@@ -206,6 +278,8 @@ type Statement struct {
 func (s Statement) phrase() {}
 
 type Placeholder struct {
+	Span
+
 	Kind string   `json:"kind" parser:"Placeholder"`
 	Name []string `json:"name" parser:"@FactID"`
 	For  string   `json:"for"  parser:"For @FactID"`
@@ -221,6 +295,8 @@ func (b *IsInvariant) Capture(values []string) error {
 }
 
 type Predicate struct {
+	Span
+
 	Kind        string      `json:"kind"                   parser:""`
 	IsInvariant IsInvariant `json:"is-invariant,omitempty" parser:"@(Invariant | Predicate)"`
 	Name        string      `json:"name"                   parser:"@FactID"`
@@ -230,6 +306,8 @@ type Predicate struct {
 func (p Predicate) phrase() {}
 
 type Event struct {
+	Span
+
 	Kind          string       `json:"kind"                     parser:"Event" default:"Event"`
 	Name          string       `json:"name"                     parser:"@FactID"`
 	RelatedTo     []string     `json:"related-to,omitempty"     parser:"(RelatedTo @(DecoratedFactID | FactID) ( Comma @(DecoratedFactID | FactID) )*)?"`
@@ -245,6 +323,8 @@ type Event struct {
 func (e Event) phrase() {}
 
 type Act struct {
+	Span
+
 	Kind          string       `json:"kind"                     parser:"Act" default:"Act"`
 	Name          string       `json:"name"                     parser:"@FactID"`
 	Actor         string       `json:"actor,omitempty"          parser:"(Actor @(DecoratedFactID | FactID))?"`
@@ -272,6 +352,8 @@ func (a Act) marshalJSON() ([]byte, error) {
 }
 
 type Duty struct {
+	Span
+
 	Kind          string       `json:"kind"                     parser:"Duty" default:"Duty"`
 	Name          string       `json:"name"                     parser:"@FactID"`
 	Holder        string       `json:"holder"                   parser:"Holder @(DecoratedFactID | FactID)"`
@@ -286,6 +368,8 @@ type Duty struct {
 func (d Duty) phrase() {}
 
 type ExtendFactDuty struct {
+	Span
+
 	Kind          string       `json:"kind"                     parser:""`
 	ParentKind    string       `json:"parent-kind"              parser:"Extend @(Fact | Duty)"`
 	Name          string       `json:"name"                     parser:"@FactID"`
@@ -297,6 +381,8 @@ type ExtendFactDuty struct {
 func (e ExtendFactDuty) phrase() {}
 
 type ExtendEventAct struct {
+	Span
+
 	Kind          string       `json:"kind"                     parser:""`
 	ParentKind    string       `json:"parent-kind"              parser:"Extend @(Event | Act)"`
 	Name          string       `json:"name"                     parser:"@FactID"`
@@ -315,9 +401,20 @@ type Expression interface {
 	expression()
 }
 
+// isToken reports whether the token is of one of the given types.
+func isToken(token *lexer.Token, types ...string) bool {
+	for _, t := range types {
+		if token.Type == eflintLexer.Symbols()[t] {
+			return true
+		}
+	}
+
+	return false
+}
+
 func parseExpressionAtom(lex *lexer.PeekingLexer) (Expression, error) {
 	switch peek := lex.Peek(); {
-	case peek.Value == "Foreach" || peek.Value == "Exists" || peek.Value == "Forall":
+	case isToken(peek, "Foreach", "Exists", "Forall"):
 		lex.Next()
 
 		binds := make([]string, 0)
@@ -350,7 +447,7 @@ func parseExpressionAtom(lex *lexer.PeekingLexer) (Expression, error) {
 			Binds:      binds,
 			Expression: expr,
 		}, nil
-	case peek.Value == "Count" || peek.Value == "Sum" || peek.Value == "Min" || peek.Value == "Max" || peek.Value == "Holds" || peek.Value == "Enabled" || peek.Value == "Not":
+	case isToken(peek, "Count", "CountDistinct", "Sum", "Min", "Max", "Avg"):
 		lex.Next()
 
 		if lex.Peek().Value != "(" {
@@ -359,7 +456,7 @@ func parseExpressionAtom(lex *lexer.PeekingLexer) (Expression, error) {
 
 		lex.Next()
 
-		if peek.Value != "Holds" && peek.Value != "Not" && peek.Value != "Enabled" && lex.Peek().Value != "Foreach" {
+		if !isToken(lex.Peek(), "Foreach") {
 			return nil, participle.Errorf(lex.Peek().Pos, "expected Foreach")
 		}
 
@@ -374,6 +471,38 @@ func parseExpressionAtom(lex *lexer.PeekingLexer) (Expression, error) {
 
 		lex.Next()
 
+		// An aggregate is an iterator over the values of the Foreach, so it
+		// takes over its binds and expression
+		foreach, ok := expr.(Iterator)
+		if !ok {
+			return nil, participle.Errorf(peek.Pos, "expected Foreach")
+		}
+
+		return Iterator{
+			Iterator:   aggregates[peek.Type],
+			Binds:      foreach.Binds,
+			Expression: foreach.Expression,
+		}, nil
+	case isToken(peek, "Holds", "Enabled", "Not"):
+		lex.Next()
+
+		if lex.Peek().Value != "(" {
+			return nil, participle.Errorf(lex.Peek().Pos, "expected (")
+		}
+
+		lex.Next()
+
+		expr, err := parseExpression(lex)
+		if err != nil {
+			return nil, err
+		}
+
+		if lex.Peek().Value != ")" {
+			return nil, participle.Errorf(lex.Peek().Pos, "expected )")
+		}
+
+		lex.Next()
+
 		return Operator{
 			Left:     expr,
 			Operator: strings.ToUpper(peek.Value),
@@ -447,6 +576,27 @@ func parseExpressionAtom(lex *lexer.PeekingLexer) (Expression, error) {
 		}
 		lex.Next()
 		return expr, nil
+	case isToken(peek, "Terminate"):
+		lex.Next()
+
+		// A minus sign directly followed by a number is a negative literal
+		if isToken(lex.Peek(), "Int") {
+			val, err := strconv.ParseInt("-"+lex.Next().Value, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return Int{val}, nil
+		}
+
+		expr, err := parseExpressionAtom(lex)
+		if err != nil {
+			return nil, err
+		}
+		return Operator{
+			Left:     expr,
+			Operator: "-",
+			Right:    nil,
+		}, nil
 	case peek.Value == "!":
 		lex.Next()
 		expr, err := parseExpressionAtom(lex)
@@ -471,7 +621,7 @@ func parseExpressionPrec(lex *lexer.PeekingLexer, minPrec int) (Expression, erro
 
 	for {
 		peek := lex.Peek()
-		prec, ok := precedences[peek.Value]
+		prec, ok := eflint.BinaryOperators[operatorNames[peek.Value]]
 		if !ok || prec.Left < minPrec {
 			break
 		}
@@ -494,12 +644,14 @@ func parseExpression(lex *lexer.PeekingLexer) (Expression, error) {
 		return nil, err
 	}
 
-	if lex.Peek().Value == "." {
+	if lex.Peek().Type == eflintLexer.Symbols()["Dot"] {
 		// TODO: Projections are ambiguous due to triggers.
+		// The dot is left alone if it ends the phrase instead, so that an
+		// expression that is nested in an iterator does not consume it.
+		check := lex.MakeCheckpoint()
+
 		lex.Next()
 		if lex.Peek().Type == eflintLexer.Symbols()["FactID"] || lex.Peek().Type == eflintLexer.Symbols()["DecoratedFactID"] {
-			check := lex.MakeCheckpoint()
-
 			id := lex.Next()
 			if lex.Peek().Value != "(" {
 				return Projection{
@@ -507,10 +659,10 @@ func parseExpression(lex *lexer.PeekingLexer) (Expression, error) {
 					Operand:   expr,
 				}, nil
 			}
-
-			lex.LoadCheckpoint(check)
 		}
-	} else if lex.Peek().Value == "When" {
+
+		lex.LoadCheckpoint(check)
+	} else if lex.Peek().Type == eflintLexer.Symbols()["When"] {
 		lex.Next()
 		rhs, err := parseExpression(lex)
 		if err != nil {
@@ -526,7 +678,9 @@ func parseExpression(lex *lexer.PeekingLexer) (Expression, error) {
 	return expr, nil
 }
 
-type Iterator struct { // TODO: Only a foreach can be inside an iterator
+// Iterator is a quantifier, such as Exists, or an aggregate, such as Count,
+// over all combinations of instances of its binds.
+type Iterator struct {
 	Iterator   string     `json:"iterator"`
 	Binds      []string   `json:"binds"`
 	Expression Expression `json:"expression"`
@@ -546,7 +700,7 @@ func (s String) MarshalJSON() ([]byte, error) {
 }
 
 type Int struct {
-	Value int64 `parser:"@Int"`
+	Value int64 `parser:"@(Terminate? Int)"`
 }
 
 func (i Int) expression() {}
@@ -669,10 +823,13 @@ func parseRangeType(r []Range) (string, bool) {
 	return rangeType, true
 }
 
-func ParseFile(filename string, file *os.File) ([]byte, error) {
-	ini, err := parser.Parse(filename, file)
+// Parse parses an eFLINT program and fills in the fields that are needed to
+// convert it to the JSON specification. Included files are parsed as well, and
+// their phrases take the place of the directive that includes them.
+func Parse(filename string, r io.Reader) (*Input, error) {
+	ini, err := newIncluder().parse(filename, r)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	// Add metadata
 	ini.Version = version
@@ -680,98 +837,152 @@ func ParseFile(filename string, file *os.File) ([]byte, error) {
 	ini.Updates = updates
 
 	// Fill in missing fields
-	for i, phrase := range (*ini).Phrases {
-		switch phrase.(type) {
-		case Fact:
-			f := phrase.(Fact)
-			if len(f.IdentifiedBy) > 0 {
-				// Composite fact
-				f.Kind = "cfact"
-			} else {
-				// Atomic fact
-				f.Kind = "afact"
-
-				if f.Type == "" {
-					if len(f.Range) > 0 {
-						rangeType, ok := parseRangeType(f.Range)
-						if !ok {
-							panic("range type mismatch")
-						}
-						f.Type = rangeType
-						rangeValues, err := parseRangeValues(f.Range, f.Tokens)
-						if err != nil {
-							panic(err)
-						}
-						f.Range = rangeValues
-					} else {
-						f.Type = "String"
+	for i, phrase := range ini.Phrases {
+		ini.Phrases[i], err = fillPhrase(phrase)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", phrase.Location().Pos, err)
+		}
+	}
+
+	return ini, nil
+}
+
+// ParseFile parses an eFLINT program and converts it to JSON.
+func ParseFile(filename string, file *os.File) ([]byte, error) {
+	ini, err := Parse(filename, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(ini, "", "  ")
+}
+
+// ParseExpressions parses a comma-separated list of expressions and converts
+// it to a JSON array.
+func ParseExpressions(filename string, src string) ([]byte, error) {
+	list, err := expressionListParser.ParseString(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	if list.Expressions == nil {
+		list.Expressions = []Expression{}
+	}
+
+	return json.Marshal(list.Expressions)
+}
+
+// ParseStatements parses a comma-separated list of statements and converts it
+// to a JSON array of phrases.
+func ParseStatements(filename string, src string) ([]byte, error) {
+	list, err := statementListParser.ParseString(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	phrases := make([]Phrase, 0, len(list.Statements))
+	for _, statement := range list.Statements {
+		phrase, err := fillPhrase(statement)
+		if err != nil {
+			return nil, err
+		}
+		phrases = append(phrases, phrase)
+	}
+
+	return json.Marshal(phrases)
+}
+
+func fillPhrase(phrase Phrase) (Phrase, error) {
+	switch phrase.(type) {
+	case Fact:
+		f := phrase.(Fact)
+		if len(f.IdentifiedBy) > 0 {
+			// Composite fact
+			f.Kind = "cfact"
+		} else {
+			// Atomic fact
+			f.Kind = "afact"
+
+			if f.Type == "" {
+				if len(f.Range) > 0 {
+					rangeType, ok := parseRangeType(f.Range)
+					if !ok {
+						return nil, fmt.Errorf("range type mismatch")
 					}
+					f.Type = rangeType
+					rangeValues, err := parseRangeValues(f.Range, f.Tokens)
+					if err != nil {
+						return nil, err
+					}
+					f.Range = rangeValues
+				} else {
+					f.Type = "String"
 				}
 			}
+		}
 
-			ini.Phrases[i] = f
-		case Query:
-			q := phrase.(Query)
-			if q.Kind == "?" {
-				q.Kind = "bquery"
-			} else if q.Kind == "?-" {
-				q.Kind = "iquery"
-			} else if q.Kind == "?--" {
-				q.Kind = "iquery"
-				q.WhenTrue = true
-			} else {
-				panic("unknown query type")
-			}
-			ini.Phrases[i] = q
-		case Statement:
-			s := phrase.(Statement)
-			if s.Kind == "+" {
-				s.Kind = "create"
-			} else if s.Kind == "-" {
-				s.Kind = "terminate"
-			} else if s.Kind == "~" {
-				s.Kind = "obfuscate"
-			} else {
-				s.Kind = "trigger"
-			}
-			ini.Phrases[i] = s
-		case Placeholder:
-			p := phrase.(Placeholder)
-			p.Kind = "placeholder"
-			ini.Phrases[i] = p
-		case Predicate:
-			p := phrase.(Predicate)
-			p.Kind = "predicate"
-			ini.Phrases[i] = p
-		case Event:
-			e := phrase.(Event)
-			e.Kind = "event"
-			ini.Phrases[i] = e
-		case Act:
-			a := phrase.(Act)
-			a.Kind = "act"
-			if a.Recipient != "" {
-				a.RelatedTo = append([]string{a.Recipient}, a.RelatedTo...)
-			}
-			if a.Actor == "" {
-				a.Actor = "actor"
-			}
-			ini.Phrases[i] = a
-		case Duty:
-			d := phrase.(Duty)
-			d.Kind = "duty"
-			ini.Phrases[i] = d
-		case ExtendEventAct:
-			e := phrase.(ExtendEventAct)
-			e.Kind = "extend"
-			ini.Phrases[i] = e
-		case ExtendFactDuty:
-			e := phrase.(ExtendFactDuty)
-			e.Kind = "extend"
-			e.ParentKind = strings.ToLower(e.ParentKind)
-			ini.Phrases[i] = e
+		return f, nil
+	case Query:
+		q := phrase.(Query)
+		if q.Kind == "?" {
+			q.Kind = "bquery"
+		} else if q.Kind == "?-" {
+			q.Kind = "iquery"
+		} else if q.Kind == "?--" {
+			q.Kind = "iquery"
+			q.WhenTrue = true
+		} else {
+			return nil, fmt.Errorf("unknown query type")
 		}
+		return q, nil
+	case Statement:
+		s := phrase.(Statement)
+		if s.Kind == "+" {
+			s.Kind = "create"
+		} else if s.Kind == "-" {
+			s.Kind = "terminate"
+		} else if s.Kind == "~" {
+			s.Kind = "obfuscate"
+		} else {
+			s.Kind = "trigger"
+		}
+		return s, nil
+	case Placeholder:
+		p := phrase.(Placeholder)
+		p.Kind = "placeholder"
+		return p, nil
+	case Predicate:
+		p := phrase.(Predicate)
+		p.Kind = "predicate"
+		return p, nil
+	case Event:
+		e := phrase.(Event)
+		e.Kind = "event"
+		return e, nil
+	case Act:
+		a := phrase.(Act)
+		a.Kind = "act"
+		if a.Recipient != "" {
+			a.RelatedTo = append([]string{a.Recipient}, a.RelatedTo...)
+		}
+		if a.Actor == "" {
+			a.Actor = "actor"
+		}
+		return a, nil
+	case Duty:
+		d := phrase.(Duty)
+		d.Kind = "duty"
+		return d, nil
+	case ExtendEventAct:
+		e := phrase.(ExtendEventAct)
+		e.Kind = "extend"
+		return e, nil
+	case ExtendFactDuty:
+		e := phrase.(ExtendFactDuty)
+		e.Kind = "extend"
+		e.ParentKind = strings.ToLower(e.ParentKind)
+		return e, nil
 	}
 
-	return json.MarshalIndent(ini, "", "  ")
+	return phrase, nil
 }
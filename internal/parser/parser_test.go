@@ -0,0 +1,240 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+)
+
+// corpus holds the programs that the server runs in its tests.
+const corpus = "../../cmd/eflint-server/tests"
+
+// parseFile parses a file in the tests directory or the corpus.
+func parseFile(t *testing.T, path string) (*Input, error) {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	return Parse(path, file)
+}
+
+// programs returns the eFLINT files in the repository.
+func programs(t *testing.T) []string {
+	t.Helper()
+
+	paths := make([]string, 0)
+	err := filepath.WalkDir("../..", func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		if !d.IsDir() && filepath.Ext(path) == ".eflint" {
+			paths = append(paths, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return paths
+}
+
+func TestRoundTrip(t *testing.T) {
+	// Parse every file, convert it to JSON, print it again and check that the
+	// printed program results in exactly the same JSON
+	for _, path := range programs(t) {
+		t.Run(path, func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			input, err := Parse(path, bytes.NewReader(src))
+			if err != nil {
+				t.Skip("cannot parse original:", err)
+			}
+
+			expected, err := json.Marshal(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var converted eflint.Input
+			if err := json.Unmarshal(expected, &converted); err != nil {
+				t.Fatal(err)
+			}
+
+			printed, err := eflint.FormatInput(converted)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reparsed, err := Parse(path, strings.NewReader(printed))
+			if err != nil {
+				t.Fatalf("cannot parse printed program: %v\n%s", err, printed)
+			}
+
+			actual, err := json.Marshal(reparsed)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(expected, actual) {
+				t.Fatalf("printed program differs from the original:\n%s", printed)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	src, err := os.ReadFile("tests/format/unformatted.eflint")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := os.ReadFile("tests/format/formatted.eflint")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	formatted, err := Format("tests/format/unformatted.eflint", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(formatted, expected) {
+		t.Fatalf("Expected:\n%s\nGot:\n%s", expected, formatted)
+	}
+
+	// Formatting keeps the meaning of every file, and formatting twice gives
+	// the same result
+	for _, path := range programs(t) {
+		t.Run(path, func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			input, err := Parse(path, bytes.NewReader(src))
+			if err != nil {
+				t.Skip("cannot parse original:", err)
+			}
+
+			formatted, err := Format(path, src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reparsed, err := Parse(path, bytes.NewReader(formatted))
+			if err != nil {
+				t.Fatalf("cannot parse formatted program: %v\n%s", err, formatted)
+			}
+
+			expected, _ := json.Marshal(input)
+			actual, _ := json.Marshal(reparsed)
+			if !bytes.Equal(expected, actual) {
+				t.Fatalf("formatted program differs from the original:\n%s", formatted)
+			}
+
+			again, err := Format(path, formatted)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(formatted, again) {
+				t.Fatalf("formatting is not stable:\n%s\n\n%s", formatted, again)
+			}
+		})
+	}
+}
+
+func TestInclude(t *testing.T) {
+	// #include and #require both include a file only once
+	input, err := parseFile(t, "tests/includes/twice.eflint")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(input.Phrases) != 3 {
+		t.Fatalf("Expected the 3 phrases of the vocabulary once, got %d", len(input.Phrases))
+	}
+
+	if filename := input.Phrases[0].Location().Pos.Filename; filename != "tests/includes/vocabulary.eflint" {
+		t.Fatalf("Expected phrases to keep the position in the included file, got %s", filename)
+	}
+
+	if _, err := parseFile(t, "tests/includes/cycle_a.eflint"); err == nil || !strings.Contains(err.Error(), "include cycle: tests/includes/cycle_a.eflint -> tests/includes/cycle_b.eflint -> tests/includes/cycle_a.eflint") {
+		t.Fatalf("Expected an include cycle, got %v", err)
+	}
+
+	if _, err := parseFile(t, "tests/includes/missing.eflint"); err == nil || !strings.HasPrefix(err.Error(), "tests/includes/missing.eflint:2:1: cannot include") {
+		t.Fatalf("Expected a missing file error, got %v", err)
+	}
+
+	for _, directive := range []string{"#include", "#require"} {
+		src := "Fact person.\n" + directive + " Vocabulary.\n"
+		if _, err := Parse("unquoted.eflint", strings.NewReader(src)); err == nil || err.Error() != "unquoted.eflint:2:1: expected a quoted path after "+directive {
+			t.Errorf("Expected an unquoted path to be rejected, got %v", err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	validate := func(path string) []Diagnostic {
+		input, err := parseFile(t, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return Validate(input)
+	}
+
+	expected := []string{
+		"4:1: error: placeholder loop refers to itself: loop -> loop",
+		"6:1: error: cannot extend vote as act: it is declared as an event at tests/validation/problems.eflint:5:1",
+		"7:1: error: cannot extend citizen: it has not been declared",
+		"8:1: warning: fact person is already declared at tests/validation/problems.eflint:2:1",
+		"9:1: warning: placeholder person shadows the fact declared at tests/validation/problems.eflint:8:1",
+		"9:1: error: placeholder person refers to itself: person -> voter -> person",
+		"10:1: error: citizen is not declared",
+	}
+
+	diagnostics := validate("tests/validation/problems.eflint")
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %v", len(expected), diagnostics)
+	}
+
+	for i, diagnostic := range diagnostics {
+		if got := strings.TrimPrefix(diagnostic.String(), "tests/validation/problems.eflint:"); got != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], got)
+		}
+	}
+
+	// The programs that the server runs are all valid
+	filepath.WalkDir(filepath.Join(corpus, "correctness"), func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".eflint" {
+			return err
+		}
+
+		if diagnostics := validate(path); HasErrors(diagnostics) {
+			t.Errorf("%s: expected no errors, got %v", path, diagnostics)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,29 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+)
+
+// ToPhrases converts the parsed phrases to the phrases of the interpreter by
+// going through the JSON specification, just like the server does. The
+// phrases are not typechecked, and have the same order as in the input.
+func ToPhrases(input *Input) ([]eflint.Phrase, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var converted eflint.Input
+	if err := json.Unmarshal(data, &converted); err != nil {
+		return nil, err
+	}
+
+	if len(converted.Phrases) != len(input.Phrases) {
+		return nil, errors.New("cannot convert phrases")
+	}
+
+	return converted.Phrases, nil
+}
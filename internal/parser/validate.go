@@ -0,0 +1,309 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// Severity is the severity of a diagnostic.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Diagnostic is a problem found by validating a program.
+type Diagnostic struct {
+	Pos      lexer.Position `json:"-"`
+	Severity Severity       `json:"severity"`
+	Message  string         `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Pos, d.Severity, d.Message)
+}
+
+// builtinFacts are declared by the interpreter before any phrase is run.
+var builtinFacts = []string{"actor", "bool", "int", "ref", "string"}
+
+// declaration is a name declared by a fact, predicate, event, act, duty or
+// placeholder.
+type declaration struct {
+	kind string
+	pos  lexer.Position
+	// For is the name a placeholder stands for
+	forName string
+}
+
+type validator struct {
+	declarations map[string]declaration
+	diagnostics  []Diagnostic
+}
+
+// Validate checks a parsed program without running it. It resolves all
+// placeholders, checks that every Extend refers to an earlier declaration of
+// the same kind and that all names refer to declared facts, and warns about
+// declarations that shadow or repeat earlier ones. The diagnostics are sorted
+// by position.
+func Validate(input *Input) []Diagnostic {
+	v := &validator{
+		declarations: make(map[string]declaration),
+		diagnostics:  make([]Diagnostic, 0),
+	}
+
+	for _, name := range builtinFacts {
+		v.declarations[name] = declaration{kind: "fact"}
+	}
+
+	// Declarations can refer to facts that are declared later on, for
+	// example to define facts in terms of each other, so these are
+	// collected before anything else is checked.
+	all := make(map[string]bool)
+	for _, phrase := range input.Phrases {
+		if name, _ := declares(phrase); name != "" {
+			all[name] = true
+		}
+	}
+
+	for _, phrase := range input.Phrases {
+		pos := phrase.Location().Pos
+
+		switch p := phrase.(type) {
+		case Fact:
+			v.declare(p.Name, "fact", pos)
+			v.names(pos, all, p.IdentifiedBy...)
+			v.clauses(pos, all, p.DerivedFrom, p.HoldsWhen, p.ConditionedBy)
+		case Predicate:
+			v.declare(p.Name, "fact", pos)
+			v.clauses(pos, all, []Expression{p.Expression})
+		case Event:
+			v.declare(p.Name, "event", pos)
+			v.names(pos, all, p.RelatedTo...)
+			v.clauses(pos, all, p.DerivedFrom, p.HoldsWhen, p.ConditionedBy, p.SyncsWith, p.Creates, p.Terminates, p.Obfuscates)
+		case Act:
+			v.declare(p.Name, "act", pos)
+			v.names(pos, all, p.Actor, p.Recipient)
+			v.names(pos, all, p.RelatedTo...)
+			v.clauses(pos, all, p.DerivedFrom, p.HoldsWhen, p.ConditionedBy, p.SyncsWith, p.Creates, p.Terminates, p.Obfuscates)
+		case Duty:
+			v.declare(p.Name, "duty", pos)
+			v.names(pos, all, p.Holder, p.Claimant)
+			v.names(pos, all, p.RelatedTo...)
+			v.clauses(pos, all, p.DerivedFrom, p.HoldsWhen, p.ConditionedBy, p.ViolatedWhen)
+		case Placeholder:
+			for _, name := range p.Name {
+				v.declarePlaceholder(name, p.For, pos)
+			}
+		case ExtendFactDuty:
+			v.extend(p.Name, p.ParentKind, pos)
+			v.clauses(pos, all, p.DerivedFrom, p.HoldsWhen, p.ConditionedBy)
+		case ExtendEventAct:
+			v.extend(p.Name, p.ParentKind, pos)
+			v.clauses(pos, all, p.DerivedFrom, p.HoldsWhen, p.ConditionedBy, p.SyncsWith, p.Creates, p.Terminates, p.Obfuscates)
+		case Query:
+			// Queries and statements are run in order, so they can only
+			// refer to facts that have been declared before
+			v.expression(pos, nil, p.Operand)
+		case Statement:
+			v.expression(pos, nil, p.Operand)
+		}
+	}
+
+	sort.SliceStable(v.diagnostics, func(i, j int) bool {
+		a, b := v.diagnostics[i].Pos, v.diagnostics[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+
+	return v.diagnostics
+}
+
+// HasErrors reports whether any of the diagnostics is an error.
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// declares returns the name and kind of the declaration made by a phrase.
+func declares(phrase Phrase) (string, string) {
+	switch p := phrase.(type) {
+	case Fact:
+		return p.Name, "fact"
+	case Predicate:
+		return p.Name, "fact"
+	case Event:
+		return p.Name, "event"
+	case Act:
+		return p.Name, "act"
+	case Duty:
+		return p.Name, "duty"
+	case Placeholder:
+		if len(p.Name) > 0 {
+			return p.Name[0], "placeholder"
+		}
+	}
+
+	return "", ""
+}
+
+func (v *validator) report(pos lexer.Position, severity Severity, format string, args ...any) {
+	diagnostic := Diagnostic{
+		Pos:      pos,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	}
+
+	// A name can be used several times in the same phrase
+	for _, d := range v.diagnostics {
+		if d == diagnostic {
+			return
+		}
+	}
+
+	v.diagnostics = append(v.diagnostics, diagnostic)
+}
+
+func (v *validator) declare(name string, kind string, pos lexer.Position) {
+	// Built-in facts, such as actor, are meant to be redeclared
+	if previous, ok := v.declarations[name]; ok && previous.pos.Line > 0 {
+		switch {
+		case previous.pos == pos:
+			v.report(pos, SeverityWarning, "%s %s is declared again because its file is included more than once", kind, name)
+		case previous.kind == "placeholder":
+			v.report(pos, SeverityWarning, "%s %s shadows the placeholder declared at %s", kind, name, previous.pos)
+		default:
+			v.report(pos, SeverityWarning, "%s %s is already declared at %s", kind, name, previous.pos)
+		}
+	}
+
+	v.declarations[name] = declaration{kind: kind, pos: pos}
+}
+
+func (v *validator) declarePlaceholder(name string, forName string, pos lexer.Position) {
+	if previous, ok := v.declarations[name]; ok {
+		if previous.kind == "placeholder" {
+			v.report(pos, SeverityWarning, "placeholder %s is already declared at %s", name, previous.pos)
+		} else {
+			v.report(pos, SeverityWarning, "placeholder %s shadows the %s declared at %s", name, previous.kind, previous.pos)
+		}
+	}
+
+	v.declarations[name] = declaration{kind: "placeholder", pos: pos, forName: forName}
+
+	if _, err := v.resolve(name); err != nil {
+		v.report(pos, SeverityError, "%v", err)
+	}
+}
+
+// resolve follows placeholders until it finds the declaration a name refers
+// to. Decorations, such as the digits in person1, are ignored.
+func (v *validator) resolve(name string) (declaration, error) {
+	seen := make([]string, 0)
+
+	for {
+		d, ok := v.declarations[name]
+		if !ok {
+			undecorated := strings.TrimRight(name, "'0123456789")
+			if d, ok = v.declarations[undecorated]; !ok {
+				return declaration{}, fmt.Errorf("%s is not declared", name)
+			}
+			name = undecorated
+		}
+
+		if d.kind != "placeholder" {
+			return d, nil
+		}
+
+		for _, s := range seen {
+			if s == name {
+				return declaration{}, fmt.Errorf("placeholder %s refers to itself: %s", name, strings.Join(append(seen, name), " -> "))
+			}
+		}
+
+		seen = append(seen, name)
+		name = d.forName
+	}
+}
+
+func (v *validator) extend(name string, parentKind string, pos lexer.Position) {
+	d, err := v.resolve(name)
+	if err != nil {
+		v.report(pos, SeverityError, "cannot extend %s: it has not been declared", name)
+		return
+	}
+
+	if kind := strings.ToLower(parentKind); d.kind != kind {
+		v.report(pos, SeverityError, "cannot extend %s as %s: it is declared as %s at %s", name, kind, article(d.kind), d.pos)
+	}
+}
+
+// names checks that names refer to facts. Names that are declared later on
+// are allowed if they are in the given set.
+func (v *validator) names(pos lexer.Position, later map[string]bool, names ...string) {
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+
+		if _, err := v.resolve(name); err != nil {
+			if later[name] || later[strings.TrimRight(name, "'0123456789")] {
+				continue
+			}
+			v.report(pos, SeverityError, "%v", err)
+		}
+	}
+}
+
+func (v *validator) clauses(pos lexer.Position, later map[string]bool, clauses ...[]Expression) {
+	for _, clause := range clauses {
+		for _, expression := range clause {
+			v.expression(pos, later, expression)
+		}
+	}
+}
+
+// expression checks that all fact names used in an expression are declared.
+func (v *validator) expression(pos lexer.Position, later map[string]bool, expression Expression) {
+	switch e := expression.(type) {
+	case Reference:
+		v.names(pos, later, e.Value)
+	case ConstructorApplication:
+		v.names(pos, later, e.Identifier)
+		for _, operand := range e.Operands {
+			v.expression(pos, later, operand)
+		}
+	case Operator:
+		if e.Left != nil {
+			v.expression(pos, later, e.Left)
+		}
+		if e.Right != nil {
+			v.expression(pos, later, e.Right)
+		}
+	case Iterator:
+		v.names(pos, later, e.Binds...)
+		v.expression(pos, later, e.Expression)
+	case Projection:
+		v.names(pos, later, e.Parameter)
+		v.expression(pos, later, e.Operand)
+	}
+}
+
+func article(kind string) string {
+	if strings.ContainsAny(kind[:1], "aeiou") {
+		return "an " + kind
+	}
+
+	return "a " + kind
+}
@@ -0,0 +1,375 @@
+// Package scenario runs eFLINT scenario files. A scenario file is a regular
+// eFLINT program in which comments state the expected outcome of the phrase
+// that precedes them (or that ends on the same line):
+//
+//	?Holds(jackpot(Alice)).
+//	// expect true
+//	?-jackpot.
+//	// expect instances: jackpot(Alice), jackpot(Bob)
+//	win(Alice).
+//	// expect changes: +jackpot(Alice), -input(6)
+//	// expect violations: pay(Alice, Bob)
+//
+// Instances, changes and violations are compared as sets, so their order does
+// not matter. An empty list, as in "// expect violations:", asserts that there
+// are none.
+package scenario
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/parser"
+)
+
+var directive = regexp.MustCompile(`//\s*expect\s+(true|false|instances|changes|violations)\s*(:(.*))?$`)
+
+// Expectation is a single assertion about the result of a phrase.
+type Expectation struct {
+	Line  int
+	Kind  string
+	Value string
+}
+
+// Failure describes an expectation that was not met.
+type Failure struct {
+	Filename string
+	Line     int
+	Phrase   int
+	Message  string
+	Missing  []string
+	Extra    []string
+}
+
+func (f Failure) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s:%d: %s", f.Filename, f.Line, f.Message)
+
+	if len(f.Missing) > 0 || len(f.Extra) > 0 {
+		b.WriteString(" (-expected +actual)")
+	}
+
+	for _, missing := range f.Missing {
+		fmt.Fprintf(&b, "\n\t- %s", missing)
+	}
+
+	for _, extra := range f.Extra {
+		fmt.Fprintf(&b, "\n\t+ %s", extra)
+	}
+
+	return b.String()
+}
+
+// Report contains the outcome of running a scenario.
+type Report struct {
+	Filename     string
+	Phrases      int
+	Expectations int
+	Failures     []Failure
+}
+
+// Passed reports whether all expectations of the scenario were met.
+func (r Report) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Run parses and executes the scenario in src and checks all of its
+// expectations. An error is returned when the scenario cannot be parsed.
+func Run(filename string, src []byte) (report Report, err error) {
+	report = Report{Filename: filename, Failures: make([]Failure, 0)}
+
+	input, err := parser.Parse(filename, bytes.NewReader(src))
+	if err != nil {
+		return report, err
+	}
+
+	expectations, err := collectExpectations(filename, src, input.Phrases)
+	if err != nil {
+		return report, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	phrases, err := parser.ToPhrases(input)
+	if err != nil {
+		return report, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	if err := eflint.TypecheckPhrases(phrases); err != nil {
+		return report, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	report.Phrases = len(phrases)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s: interpreter failed: %v", filename, r)
+		}
+	}()
+
+	eflint.Reset()
+
+	for i, phrase := range phrases {
+		pos := input.Phrases[i].Location().Pos
+
+		if err := eflint.InterpretPhrase(phrase); err != nil {
+			report.Failures = append(report.Failures, Failure{
+				Filename: pos.Filename,
+				Line:     pos.Line,
+				Phrase:   i,
+				Message:  fmt.Sprintf("phrase failed: %v", err),
+			})
+		}
+
+		results := eflint.Results()
+		result := results[len(results)-1]
+
+		for _, expectation := range expectations[i] {
+			report.Expectations++
+
+			if failure := check(filename, phrase, result, expectation); failure != nil {
+				failure.Phrase = i
+				report.Failures = append(report.Failures, *failure)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// collectExpectations scans the source for expectation comments and attaches
+// each of them to the last phrase of the scenario itself that starts on or
+// before its line. Phrases from included files are skipped.
+func collectExpectations(filename string, src []byte, phrases []parser.Phrase) (map[int][]Expectation, error) {
+	expectations := make(map[int][]Expectation)
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+
+	for line := 1; scanner.Scan(); line++ {
+		match := directive.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+
+		index := -1
+		for i, phrase := range phrases {
+			if phrase.Location().Pos.Filename != filename {
+				continue
+			}
+			if phrase.Location().Pos.Line > line {
+				break
+			}
+			index = i
+		}
+
+		if index < 0 {
+			return nil, fmt.Errorf("line %d: expectation does not follow a phrase", line)
+		}
+
+		expectations[index] = append(expectations[index], Expectation{
+			Line:  line,
+			Kind:  match[1],
+			Value: strings.TrimSpace(match[3]),
+		})
+	}
+
+	return expectations, scanner.Err()
+}
+
+func check(filename string, phrase eflint.Phrase, result eflint.PhraseResult, expectation Expectation) (failure *Failure) {
+	fail := func(message string, args ...any) *Failure {
+		return &Failure{
+			Filename: filename,
+			Line:     expectation.Line,
+			Message:  fmt.Sprintf(message, args...),
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			failure = fail("cannot check expectation: %v", r)
+		}
+	}()
+
+	switch expectation.Kind {
+	case "true", "false":
+		if phrase.Kind != "bquery" {
+			return fail("expect %s only applies to boolean queries", expectation.Kind)
+		}
+
+		if expected := expectation.Kind == "true"; result.Result != expected {
+			return fail("expected query to be %t, got %t", expected, result.Result)
+		}
+	case "instances":
+		if phrase.Kind != "iquery" {
+			return fail("expect instances only applies to instance queries")
+		}
+
+		expected, err := parseInstances(filename, expectation.Value)
+		if err != nil {
+			return fail("%v", err)
+		}
+
+		actual := make([]string, 0, len(result.Results))
+		for _, instance := range result.Results {
+			formatted, err := eflint.FormatInstance(instance)
+			if err != nil {
+				return fail("%v", err)
+			}
+			actual = append(actual, formatted)
+		}
+
+		if missing, extra := difference(expected, actual); len(missing) > 0 || len(extra) > 0 {
+			failure = fail("instances differ")
+			failure.Missing, failure.Extra = missing, extra
+		}
+	case "violations":
+		expected, err := parseInstances(filename, expectation.Value)
+		if err != nil {
+			return fail("%v", err)
+		}
+
+		actual := make([]string, 0, len(result.Violations))
+		for _, violation := range result.Violations {
+			formatted, err := eflint.FormatInstance(eflint.Expression{
+				Identifier: violation.Identifier,
+				Operands:   violation.Operands,
+			})
+			if err != nil {
+				return fail("%v", err)
+			}
+			actual = append(actual, formatted)
+		}
+
+		if missing, extra := difference(expected, actual); len(missing) > 0 || len(extra) > 0 {
+			failure = fail("violations differ")
+			failure.Missing, failure.Extra = missing, extra
+		}
+	case "changes":
+		expected, err := parseChanges(filename, expectation.Value)
+		if err != nil {
+			return fail("%v", err)
+		}
+
+		actual := make([]string, 0, len(result.Changes))
+		for _, change := range result.Changes {
+			// Declarations are reported as changes as well, but cannot be expected
+			if change.Operand == nil {
+				continue
+			}
+
+			formatted, err := formatChange(change)
+			if err != nil {
+				return fail("%v", err)
+			}
+			actual = append(actual, formatted)
+		}
+
+		if missing, extra := difference(expected, actual); len(missing) > 0 || len(extra) > 0 {
+			failure = fail("changes differ")
+			failure.Missing, failure.Extra = missing, extra
+		}
+	}
+
+	return failure
+}
+
+func parseInstances(filename string, src string) ([]string, error) {
+	data, err := parser.ParseExpressions(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var expressions []eflint.Expression
+	if err := json.Unmarshal(data, &expressions); err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(expressions))
+	for _, expression := range expressions {
+		formatted, err := eflint.FormatInstance(expression)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, formatted)
+	}
+
+	return result, nil
+}
+
+func parseChanges(filename string, src string) ([]string, error) {
+	data, err := parser.ParseStatements(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var phrases []eflint.Phrase
+	if err := json.Unmarshal(data, &phrases); err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(phrases))
+	for _, phrase := range phrases {
+		formatted, err := formatChange(phrase)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, formatted)
+	}
+
+	return result, nil
+}
+
+func formatChange(change eflint.Phrase) (string, error) {
+	prefixes := map[string]string{
+		"create":    "+",
+		"terminate": "-",
+		"obfuscate": "~",
+	}
+
+	prefix, ok := prefixes[change.Kind]
+	if !ok {
+		return "", fmt.Errorf("%s is not a change", change.Kind)
+	}
+
+	formatted, err := eflint.FormatInstance(*change.Operand)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + formatted, nil
+}
+
+// difference compares two multisets of strings, and returns the elements that
+// are only expected and the elements that are only found, both sorted.
+func difference(expected []string, actual []string) ([]string, []string) {
+	counts := make(map[string]int)
+
+	for _, e := range expected {
+		counts[e]++
+	}
+
+	for _, a := range actual {
+		counts[a]--
+	}
+
+	missing, extra := make([]string, 0), make([]string, 0)
+
+	for value, count := range counts {
+		for ; count > 0; count-- {
+			missing = append(missing, value)
+		}
+		for ; count < 0; count++ {
+			extra = append(extra, value)
+		}
+	}
+
+	sort.Strings(missing)
+	sort.Strings(extra)
+
+	return missing, extra
+}
@@ -0,0 +1,37 @@
+package scenario
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScenarios(t *testing.T) {
+	filepath.WalkDir("tests", func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		t.Run(path, func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := Run(path, src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, failure := range report.Failures {
+				t.Error(failure)
+			}
+		})
+
+		return nil
+	})
+}
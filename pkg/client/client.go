@@ -0,0 +1,268 @@
+// Package client is a client for the eFLINT server. It builds phrases and
+// expressions in the JSON specification, sends them over HTTP or in a
+// WebSocket session, and decodes the results:
+//
+//	c := client.New("http://localhost:8080")
+//	if _, err := c.Handshake(ctx); err != nil {
+//		return err
+//	}
+//
+//	output, err := c.Phrases(ctx,
+//		client.AtomicFact("citizen", "String"),
+//		client.Create(client.Apply("citizen", client.String("Alice"))),
+//		client.BQuery(client.Apply("citizen", client.String("Alice"))),
+//	)
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SupportedVersions are the versions of the JSON specification that the
+// client understands, from old to new.
+var SupportedVersions = []string{"0.1.0"}
+
+// SupportedExtensions are the server extensions that the client asks for if
+// the server supports them.
+var SupportedExtensions = []string{"result-kind"}
+
+// ErrNoCommonVersion is returned by Handshake when the client and server do
+// not support the same version of the JSON specification.
+var ErrNoCommonVersion = errors.New("no common version of the JSON specification")
+
+// Input is a request to the server.
+type Input struct {
+	Version string   `json:"version"`
+	Kind    string   `json:"kind"`
+	Phrases []Phrase `json:"-"`
+	Updates bool     `json:"updates,omitempty"`
+
+	// Exploration fields
+	Depth int         `json:"depth,omitempty"`
+	Goal  *Expression `json:"goal,omitempty"`
+
+	// Extensions are the server extensions that the input asks for
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+// MarshalJSON leaves out the phrases if they are nil, as requests such as
+// pings must not have them, while an empty list is sent as is.
+func (i Input) MarshalJSON() ([]byte, error) {
+	type alias Input
+	aux := struct {
+		alias
+		Phrases *[]Phrase `json:"phrases,omitempty"`
+	}{alias: alias(i)}
+
+	if i.Phrases != nil {
+		aux.Phrases = &i.Phrases
+	}
+
+	return json.Marshal(aux)
+}
+
+type Client struct {
+	// URL is the address of the server, such as http://localhost:8080.
+	URL        string
+	HTTPClient *http.Client
+	// Version is the version of the JSON specification that requests use.
+	// It is the newest supported version until Handshake negotiates one.
+	Version string
+	// Extensions are the server extensions that requests ask for. There are
+	// none until Handshake finds those that the server supports.
+	Extensions []string
+}
+
+func New(url string) *Client {
+	return &Client{
+		URL:        strings.TrimSuffix(url, "/"),
+		HTTPClient: http.DefaultClient,
+		Version:    SupportedVersions[len(SupportedVersions)-1],
+	}
+}
+
+// Handshake asks the server for its handshake, and switches to the newest
+// version of the JSON specification that both the client and the server
+// support, with the extensions that both support. The server is asked for any of the versions of the client at once,
+// and picks the newest one it supports. Servers that do not understand
+// version ranges only answer handshakes in a version they support, so then the
+// versions of the client are tried one by one, from new to old.
+func (c *Client) Handshake(ctx context.Context) (*Handshake, error) {
+	handshake, err := c.handshake(ctx, strings.Join(SupportedVersions, " || "))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(SupportedVersions) - 1; i >= 0 && !handshake.Success; i-- {
+		if handshake, err = c.handshake(ctx, SupportedVersions[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if !handshake.Success {
+		return nil, fmt.Errorf("%w: the server rejected versions %s", ErrNoCommonVersion,
+			strings.Join(SupportedVersions, ", "))
+	}
+
+	version := handshake.Version
+	if version == "" {
+		var ok bool
+		if version, ok = NegotiateVersion(SupportedVersions, handshake.SupportedVersions); !ok {
+			return handshake, fmt.Errorf("%w: the client supports %s, the server %s", ErrNoCommonVersion,
+				strings.Join(SupportedVersions, ", "), strings.Join(handshake.SupportedVersions, ", "))
+		}
+	}
+
+	c.Version = version
+
+	c.Extensions = nil
+	for _, extension := range SupportedExtensions {
+		for _, other := range handshake.Extensions {
+			if extension == other {
+				c.Extensions = append(c.Extensions, extension)
+			}
+		}
+	}
+
+	return handshake, nil
+}
+
+func (c *Client) handshake(ctx context.Context, version string) (*Handshake, error) {
+	body, _, err := c.post(ctx, Input{Version: version, Kind: "handshake"})
+	if err != nil {
+		return nil, err
+	}
+
+	var handshake Handshake
+	if err := json.Unmarshal(body, &handshake); err != nil {
+		return nil, err
+	}
+
+	return &handshake, nil
+}
+
+// NegotiateVersion returns the newest version that is in both lists.
+func NegotiateVersion(ours []string, theirs []string) (string, bool) {
+	best := ""
+
+	for _, version := range ours {
+		for _, other := range theirs {
+			if version == other && (best == "" || compareVersions(version, best) > 0) {
+				best = version
+			}
+		}
+	}
+
+	return best, best != ""
+}
+
+// compareVersions compares two versions of the form major.minor.patch
+// numerically.
+func compareVersions(a string, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, Input{Kind: "ping"})
+	return err
+}
+
+// Phrases runs the phrases, starting from an empty state, and returns the
+// result of every phrase.
+func (c *Client) Phrases(ctx context.Context, phrases ...Phrase) (*Output, error) {
+	if phrases == nil {
+		phrases = []Phrase{}
+	}
+
+	return c.Do(ctx, Input{Kind: "phrases", Phrases: phrases})
+}
+
+// Do sends a request of any kind apart from handshake, using the version and
+// extensions of the client if the input has none. It returns a RequestError
+// if the server could not handle it.
+func (c *Client) Do(ctx context.Context, input Input) (*Output, error) {
+	if input.Version == "" {
+		input.Version = c.Version
+	}
+	if input.Extensions == nil {
+		input.Extensions = c.Extensions
+	}
+
+	body, status, err := c.post(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var output Output
+	if err := json.Unmarshal(body, &output); err != nil {
+		return nil, err
+	}
+
+	if !output.Success {
+		return &output, &RequestError{StatusCode: status, Errors: output.Errors}
+	}
+
+	return &output, nil
+}
+
+// post sends an input, and returns the body and status of the response. Inputs
+// that the server rejects are answered with an output with errors, which is
+// returned as well.
+func (c *Client) post(ctx context.Context, input Input) ([]byte, int, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL+"/", bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rejected := response.StatusCode >= 400 && response.StatusCode < 500 &&
+		strings.HasPrefix(response.Header.Get("Content-Type"), "application/json")
+	if response.StatusCode != http.StatusOK && !rejected {
+		return nil, 0, fmt.Errorf("the server responded with %s: %s", response.Status, strings.TrimSpace(string(body)))
+	}
+
+	return body, response.StatusCode, nil
+}
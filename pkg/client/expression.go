@@ -0,0 +1,153 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Expression is an eFLINT expression in the JSON specification. It is a
+// primitive value or variable reference if Value is set, and otherwise a
+// constructor application, operator, iterator or projection, depending on
+// which of the other fields are set. The functions below build expressions.
+type Expression struct {
+	Value      interface{}  `json:"value,omitempty"`
+	Operator   string       `json:"operator,omitempty"`
+	Identifier string       `json:"identifier,omitempty"`
+	Operands   []Expression `json:"operands,omitempty"`
+	Iterator   string       `json:"iterator,omitempty"`
+	Binds      []string     `json:"binds,omitempty"`
+	Expression *Expression  `json:"expression,omitempty"`
+	Operand    *Expression  `json:"operand,omitempty"`
+	Parameter  string       `json:"parameter,omitempty"`
+}
+
+// String returns a string literal.
+func String(value string) Expression {
+	return Expression{Value: value}
+}
+
+// Int returns an integer literal.
+func Int(value int64) Expression {
+	return Expression{Value: value}
+}
+
+// Bool returns a boolean literal.
+func Bool(value bool) Expression {
+	return Expression{Value: value}
+}
+
+// Var returns a reference to a variable, or to a fact without parameters.
+func Var(name string) Expression {
+	return Expression{Value: []string{name}}
+}
+
+// Apply returns the application of a fact constructor, such as
+// citizen("Alice").
+func Apply(identifier string, operands ...Expression) Expression {
+	return Expression{Identifier: identifier, Operands: operands}
+}
+
+// Op returns an operator applied to its operands, such as Op("GT", x, y).
+func Op(operator string, operands ...Expression) Expression {
+	return Expression{Operator: operator, Operands: operands}
+}
+
+func Not(operand Expression) Expression {
+	return Op("NOT", operand)
+}
+
+func And(operands ...Expression) Expression {
+	return Op("AND", operands...)
+}
+
+func Or(operands ...Expression) Expression {
+	return Op("OR", operands...)
+}
+
+func Holds(operand Expression) Expression {
+	return Op("HOLDS", operand)
+}
+
+// Iterate returns an iterator, such as EXISTS or COUNT, over the given
+// variables.
+func Iterate(iterator string, binds []string, expression Expression) Expression {
+	return Expression{Iterator: iterator, Binds: binds, Expression: &expression}
+}
+
+func Exists(binds []string, expression Expression) Expression {
+	return Iterate("EXISTS", binds, expression)
+}
+
+func Forall(binds []string, expression Expression) Expression {
+	return Iterate("FORALL", binds, expression)
+}
+
+// Project returns the parameter of an instance, such as the holder of a duty.
+func Project(parameter string, operand Expression) Expression {
+	return Expression{Parameter: parameter, Operand: &operand}
+}
+
+// Name returns the name of the fact of an instance.
+func (e Expression) Name() string {
+	if ref, ok := e.Value.([]string); ok && len(ref) == 1 {
+		return ref[0]
+	}
+
+	return e.Identifier
+}
+
+func (e Expression) MarshalJSON() ([]byte, error) {
+	if e.Value != nil {
+		return json.Marshal(e.Value)
+	}
+
+	type alias Expression
+	return json.Marshal(alias(e))
+}
+
+func (e *Expression) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return fmt.Errorf("empty expression")
+	}
+
+	switch data[0] {
+	case '"':
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*e = String(value)
+	case 't', 'f':
+		var value bool
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*e = Bool(value)
+	case '[':
+		var ref []string
+		if err := json.Unmarshal(data, &ref); err != nil {
+			return err
+		}
+		if len(ref) != 1 {
+			return fmt.Errorf("variable reference with %d names", len(ref))
+		}
+		*e = Var(ref[0])
+	case '{':
+		type alias Expression
+		var a alias
+		if err := json.Unmarshal(data, &a); err != nil {
+			return err
+		}
+		*e = Expression(a)
+	default:
+		var value int64
+		if err := json.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("unknown expression %s", data)
+		}
+		*e = Int(value)
+	}
+
+	return nil
+}
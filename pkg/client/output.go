@@ -0,0 +1,254 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Output is the response to a request.
+type Output struct {
+	Success   bool         `json:"success"`
+	Errors    []Error      `json:"errors,omitempty"`
+	Results   []Result     `json:"-"`
+	Duties    []DutyGroup  `json:"duties,omitempty"`
+	Traces    []Trace      `json:"traces,omitempty"`
+	Graph     *Graph       `json:"graph,omitempty"`
+	Dot       string       `json:"dot,omitempty"`
+	Instances []Expression `json:"instances,omitempty"`
+}
+
+// Error is an error of a request or phrase. Code is set for requests that the
+// server rejects, and is one of the Code constants.
+type Error struct {
+	Id      string `json:"id"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+	// Pointer is the JSON pointer to the field of the request that the error
+	// is about, if any
+	Pointer string `json:"pointer,omitempty"`
+}
+
+// Result is the result of a phrase: a BQueryResult for boolean queries, an
+// IQueryResult for instance queries, and StateChanges for everything else.
+type Result interface {
+	Succeeded() bool
+}
+
+type BQueryResult struct {
+	Success bool    `json:"success"`
+	Errors  []Error `json:"errors,omitempty"`
+	Result  bool    `json:"result"`
+}
+
+type IQueryResult struct {
+	Success bool         `json:"success"`
+	Errors  []Error      `json:"errors,omitempty"`
+	Result  []Expression `json:"result"`
+}
+
+type StateChanges struct {
+	Success    bool        `json:"success"`
+	Errors     []Error     `json:"errors,omitempty"`
+	Changes    []Phrase    `json:"changes"`
+	Triggers   []Triggered `json:"triggers"`
+	Violated   bool        `json:"violated"`
+	Violations []Violation `json:"violations"`
+}
+
+func (r BQueryResult) Succeeded() bool { return r.Success }
+func (r IQueryResult) Succeeded() bool { return r.Success }
+func (r StateChanges) Succeeded() bool { return r.Success }
+
+// Triggered is an act, event or duty that was triggered by a phrase, either
+// directly or through the synchronisation of another.
+type Triggered struct {
+	Identifier string `json:"identifier"`
+	Kind       string `json:"kind"`
+	Parent     string `json:"parent"`
+}
+
+type Violation struct {
+	Kind       string       `json:"kind"`
+	Identifier string       `json:"identifier"`
+	Operands   []Expression `json:"operands"`
+}
+
+type DutyGroup struct {
+	Holder   Expression     `json:"holder"`
+	Claimant Expression     `json:"claimant"`
+	Duties   []DutyInstance `json:"duties"`
+}
+
+type DutyInstance struct {
+	Duty     Expression `json:"duty"`
+	Violated bool       `json:"violated"`
+}
+
+type Trace struct {
+	Kind       string      `json:"kind"`
+	Phrases    []Phrase    `json:"phrases"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+type GraphNode struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+type Handshake struct {
+	Success           bool     `json:"success"`
+	SupportedVersions []string `json:"supported_versions"`
+	Reasoner          string   `json:"reasoner"`
+	ReasonerVersion   string   `json:"reasoner_version"`
+	SharesUpdates     bool     `json:"shares_updates"`
+	SharesTriggers    bool     `json:"shares_triggers"`
+	SharesViolations  bool     `json:"shares_violations"`
+	// Version is the version that the server picked from those of the
+	// handshake request
+	Version string `json:"version,omitempty"`
+	// Extensions are the server extensions that requests can ask for
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+func (o *Output) UnmarshalJSON(data []byte) error {
+	type alias Output
+	aux := struct {
+		*alias
+		Results []json.RawMessage `json:"results"`
+	}{alias: (*alias)(o)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	o.Results = make([]Result, 0, len(aux.Results))
+	for _, raw := range aux.Results {
+		result, err := decodeResult(raw)
+		if err != nil {
+			return err
+		}
+		o.Results = append(o.Results, result)
+	}
+
+	return nil
+}
+
+// decodeResult decodes the result of a phrase. With the result-kind extension
+// the server names its kind; otherwise it is told apart by its fields: boolean
+// queries have a boolean result, instance queries a list of instances, and
+// state changes have no result.
+func decodeResult(data []byte) (Result, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	if kind, ok := fields["kind"]; ok {
+		var name string
+		if err := json.Unmarshal(kind, &name); err != nil {
+			return nil, err
+		}
+
+		switch name {
+		case "bquery":
+			var bquery BQueryResult
+			err := json.Unmarshal(data, &bquery)
+			return bquery, err
+		case "iquery":
+			var iquery IQueryResult
+			err := json.Unmarshal(data, &iquery)
+			return iquery, err
+		case "changes":
+			var changes StateChanges
+			err := json.Unmarshal(data, &changes)
+			return changes, err
+		default:
+			return nil, fmt.Errorf("unknown result kind %s", name)
+		}
+	}
+
+	result, ok := fields["result"]
+	if !ok {
+		var changes StateChanges
+		err := json.Unmarshal(data, &changes)
+		return changes, err
+	}
+
+	switch result = bytes.TrimSpace(result); {
+	case bytes.Equal(result, []byte("true")) || bytes.Equal(result, []byte("false")):
+		var bquery BQueryResult
+		err := json.Unmarshal(data, &bquery)
+		return bquery, err
+	case bytes.HasPrefix(result, []byte("[")) || bytes.Equal(result, []byte("null")):
+		var iquery IQueryResult
+		err := json.Unmarshal(data, &iquery)
+		return iquery, err
+	default:
+		return nil, fmt.Errorf("unknown result %s", result)
+	}
+}
+
+// The codes of the errors that the server rejects requests with.
+const (
+	CodeInvalidJSON         = "invalid_json"
+	CodeMissingField        = "missing_field"
+	CodeUnknownField        = "unknown_field"
+	CodeInvalidValue        = "invalid_value"
+	CodeUnknownKind         = "unknown_kind"
+	CodeMalformedExpression = "malformed_expression"
+	CodeUnsupportedVersion  = "unsupported_version"
+	CodeUnsupportedFields   = "unsupported_fields"
+	CodeInvalidDepth        = "invalid_depth"
+	CodeTypecheckFailed     = "typecheck_failed"
+	CodeMethodNotAllowed    = "method_not_allowed"
+	CodeUnknownSession      = "unknown_session"
+	CodeInterpreterError    = "interpreter_error"
+)
+
+// RequestError is returned when the server could not handle a request.
+// StatusCode is the HTTP status of the response, if it was sent over HTTP.
+type RequestError struct {
+	StatusCode int
+	Errors     []Error
+}
+
+// HasCode returns whether any of the errors has the code.
+func (e *RequestError) HasCode(code string) bool {
+	for _, err := range e.Errors {
+		if err.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *RequestError) Error() string {
+	if len(e.Errors) == 0 {
+		return "the request failed"
+	}
+
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		if err.Pointer != "" {
+			messages = append(messages, fmt.Sprintf("%s: %s: %s", err.Id, err.Pointer, err.Message))
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", err.Id, err.Message))
+	}
+
+	return "the request failed: " + strings.Join(messages, "; ")
+}
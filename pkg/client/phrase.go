@@ -0,0 +1,157 @@
+package client
+
+// Phrase is a query, statement or definition in the JSON specification. The
+// functions below build phrases of every kind; the clauses of definitions,
+// such as HoldsWhen, are passed as extra arguments.
+type Phrase struct {
+	Kind      string `json:"kind"`
+	Stateless bool   `json:"stateless,omitempty"`
+	Updates   bool   `json:"updates,omitempty"`
+
+	// Query fields
+	Expression *Expression `json:"expression,omitempty"`
+
+	// Statement fields
+	Operand *Expression `json:"operand,omitempty"`
+
+	// Definition fields. Name is a string, apart from placeholders, which
+	// have a list of names.
+	Name          interface{}  `json:"name,omitempty"`
+	Type          string       `json:"type,omitempty"`
+	Range         []Expression `json:"range,omitempty"`
+	WhenTrue      bool         `json:"when-true,omitempty"`
+	DerivedFrom   []Expression `json:"derived-from,omitempty"`
+	HoldsWhen     []Expression `json:"holds-when,omitempty"`
+	ConditionedBy []Expression `json:"conditioned-by,omitempty"`
+	IdentifiedBy  []string     `json:"identified-by,omitempty"`
+	For           string       `json:"for,omitempty"`
+	IsInvariant   bool         `json:"is-invariant,omitempty"`
+	RelatedTo     []string     `json:"related-to,omitempty"`
+	SyncsWith     []Expression `json:"syncs-with,omitempty"`
+	Creates       []Expression `json:"creates,omitempty"`
+	Terminates    []Expression `json:"terminates,omitempty"`
+	Obfuscates    []Expression `json:"obfuscates,omitempty"`
+	Actor         string       `json:"actor,omitempty"`
+	Holder        string       `json:"holder,omitempty"`
+	Claimant      string       `json:"claimant,omitempty"`
+	ViolatedWhen  []Expression `json:"violated-when,omitempty"`
+	ParentKind    string       `json:"parent-kind,omitempty"`
+}
+
+// Clause adds a clause, such as Holds when, to a definition.
+type Clause func(*Phrase)
+
+func DerivedFrom(expressions ...Expression) Clause {
+	return func(p *Phrase) { p.DerivedFrom = append(p.DerivedFrom, expressions...) }
+}
+
+func HoldsWhen(expressions ...Expression) Clause {
+	return func(p *Phrase) { p.HoldsWhen = append(p.HoldsWhen, expressions...) }
+}
+
+func ConditionedBy(expressions ...Expression) Clause {
+	return func(p *Phrase) { p.ConditionedBy = append(p.ConditionedBy, expressions...) }
+}
+
+func SyncsWith(expressions ...Expression) Clause {
+	return func(p *Phrase) { p.SyncsWith = append(p.SyncsWith, expressions...) }
+}
+
+func Creates(expressions ...Expression) Clause {
+	return func(p *Phrase) { p.Creates = append(p.Creates, expressions...) }
+}
+
+func Terminates(expressions ...Expression) Clause {
+	return func(p *Phrase) { p.Terminates = append(p.Terminates, expressions...) }
+}
+
+func Obfuscates(expressions ...Expression) Clause {
+	return func(p *Phrase) { p.Obfuscates = append(p.Obfuscates, expressions...) }
+}
+
+func ViolatedWhen(expressions ...Expression) Clause {
+	return func(p *Phrase) { p.ViolatedWhen = append(p.ViolatedWhen, expressions...) }
+}
+
+func RelatedTo(names ...string) Clause {
+	return func(p *Phrase) { p.RelatedTo = append(p.RelatedTo, names...) }
+}
+
+// Range restricts the instances of an atomic fact to the given values.
+func Range(values ...Expression) Clause {
+	return func(p *Phrase) { p.Range = append(p.Range, values...) }
+}
+
+func definition(phrase Phrase, clauses []Clause) Phrase {
+	for _, clause := range clauses {
+		clause(&phrase)
+	}
+
+	return phrase
+}
+
+// AtomicFact declares a fact of type String or Int.
+func AtomicFact(name string, typ string, clauses ...Clause) Phrase {
+	return definition(Phrase{Kind: "afact", Name: name, Type: typ}, clauses)
+}
+
+// CompositeFact declares a fact that is identified by other facts.
+func CompositeFact(name string, identifiedBy []string, clauses ...Clause) Phrase {
+	return definition(Phrase{Kind: "cfact", Name: name, IdentifiedBy: identifiedBy}, clauses)
+}
+
+func Placeholder(names []string, forFact string) Phrase {
+	return Phrase{Kind: "placeholder", Name: names, For: forFact}
+}
+
+func Predicate(name string, expression Expression) Phrase {
+	return Phrase{Kind: "predicate", Name: name, Expression: &expression}
+}
+
+func Invariant(name string, expression Expression) Phrase {
+	return Phrase{Kind: "predicate", Name: name, Expression: &expression, IsInvariant: true}
+}
+
+func Event(name string, clauses ...Clause) Phrase {
+	return definition(Phrase{Kind: "event", Name: name}, clauses)
+}
+
+func Act(name string, actor string, clauses ...Clause) Phrase {
+	return definition(Phrase{Kind: "act", Name: name, Actor: actor}, clauses)
+}
+
+func Duty(name string, holder string, claimant string, clauses ...Clause) Phrase {
+	return definition(Phrase{Kind: "duty", Name: name, Holder: holder, Claimant: claimant}, clauses)
+}
+
+// Extend adds clauses to an earlier definition. The parent kind is Fact,
+// Event, Act or Duty.
+func Extend(parentKind string, name string, clauses ...Clause) Phrase {
+	return definition(Phrase{Kind: "extend", ParentKind: parentKind, Name: name}, clauses)
+}
+
+func Create(operand Expression) Phrase {
+	return Phrase{Kind: "create", Operand: &operand}
+}
+
+func Terminate(operand Expression) Phrase {
+	return Phrase{Kind: "terminate", Operand: &operand}
+}
+
+func Obfuscate(operand Expression) Phrase {
+	return Phrase{Kind: "obfuscate", Operand: &operand}
+}
+
+func Trigger(operand Expression) Phrase {
+	return Phrase{Kind: "trigger", Operand: &operand}
+}
+
+// BQuery asks whether an expression holds.
+func BQuery(expression Expression) Phrase {
+	return Phrase{Kind: "bquery", Expression: &expression}
+}
+
+// IQuery asks for the instances of an expression.
+func IQuery(expression Expression) Phrase {
+	return Phrase{Kind: "iquery", Expression: &expression}
+}
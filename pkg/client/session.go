@@ -0,0 +1,219 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// Session is a WebSocket connection to a session of the server, which keeps
+// its state between inputs. Results are pushed to all connections of a
+// session, including those of inputs sent by other connections.
+type Session struct {
+	ID      string
+	client  *Client
+	conn    *websocket.Conn
+	pending []Message
+}
+
+// Message is a message that the server sends in a session. Its kind is one
+// of session, result, done, handshake or error.
+type Message struct {
+	Kind    string
+	Session string
+	// Index is the index of the phrase of a result in its input
+	Index     int
+	Result    Result
+	Output    *Output
+	Handshake *Handshake
+	Error     string
+	// Errors are the reasons that an input was rejected, if it was
+	Errors []Error
+}
+
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Kind    string          `json:"kind"`
+		Session string          `json:"session"`
+		Index   int             `json:"index"`
+		Result  json.RawMessage `json:"result"`
+		Output  *Output         `json:"output"`
+		Error   string          `json:"error"`
+		Errors  []Error         `json:"errors"`
+
+		// The handshake is sent as is, without a kind
+		SupportedVersions []string `json:"supported_versions"`
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.Kind == "" && aux.SupportedVersions != nil {
+		var handshake Handshake
+		if err := json.Unmarshal(data, &handshake); err != nil {
+			return err
+		}
+
+		*m = Message{Kind: "handshake", Handshake: &handshake}
+		return nil
+	}
+
+	*m = Message{Kind: aux.Kind, Session: aux.Session, Index: aux.Index, Output: aux.Output, Error: aux.Error, Errors: aux.Errors}
+
+	if aux.Result != nil {
+		result, err := decodeResult(aux.Result)
+		if err != nil {
+			return err
+		}
+		m.Result = result
+	}
+
+	return nil
+}
+
+// Session opens a connection to the session with the given ID, or to a new
+// session if the ID is empty.
+func (c *Client) Session(ctx context.Context, id string) (*Session, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Scheme = strings.Replace(u.Scheme, "http", "ws", 1)
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/ws"
+	if id != "" {
+		u.RawQuery = url.Values{"session": {id}}.Encode()
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Session{client: c, conn: conn}
+
+	m, err := s.Receive()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if m.Kind != "session" {
+		conn.Close()
+		return nil, fmt.Errorf("expected a session message, got %s", m.Kind)
+	}
+
+	s.ID = m.Session
+	return s, nil
+}
+
+// Send sends an input to the session, using the version and extensions of the
+// client if the input has none. Its results arrive as messages.
+func (s *Session) Send(input Input) error {
+	if input.Version == "" {
+		input.Version = s.client.Version
+	}
+	if input.Extensions == nil {
+		input.Extensions = s.client.Extensions
+	}
+
+	return s.conn.WriteJSON(input)
+}
+
+// Receive returns the next message of the session.
+func (s *Session) Receive() (*Message, error) {
+	if len(s.pending) > 0 {
+		m := s.pending[0]
+		s.pending = s.pending[1:]
+		return &m, nil
+	}
+
+	var m Message
+	if err := s.conn.ReadJSON(&m); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// Phrases runs the phrases in the session and waits until they are done. It
+// returns their results and the output, or a RequestError if the server
+// could not handle them. Messages about the inputs of other connections
+// that arrive in the meantime are kept for Receive.
+func (s *Session) Phrases(phrases ...Phrase) ([]Result, *Output, error) {
+	if phrases == nil {
+		phrases = []Phrase{}
+	}
+
+	return s.do(Input{Kind: "phrases", Phrases: phrases})
+}
+
+// Inspect returns the instances that currently hold in the session.
+func (s *Session) Inspect() ([]Expression, error) {
+	_, output, err := s.do(Input{Kind: "inspect"})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Instances, nil
+}
+
+// do sends an input to the session and waits until it is done.
+func (s *Session) do(input Input) ([]Result, *Output, error) {
+	phrases := input.Phrases
+
+	if err := s.Send(input); err != nil {
+		return nil, nil, err
+	}
+
+	// The results of an input are sent together, while the interpreter is
+	// locked, and only the connection that sent it receives the done message.
+	// Blocks of results that are not followed by it belong to the inputs of
+	// other connections, and are kept for Receive.
+	block := make([]Message, 0, len(phrases))
+	others := make([]Message, 0)
+	defer func() { s.pending = append(s.pending, others...) }()
+
+	for {
+		var m Message
+		if err := s.conn.ReadJSON(&m); err != nil {
+			return nil, nil, err
+		}
+
+		switch m.Kind {
+		case "result":
+			if m.Index == 0 {
+				others = append(others, block...)
+				block = block[:0]
+			}
+			block = append(block, m)
+		case "done":
+			if len(block) != len(phrases) {
+				others = append(others, block...)
+				block = block[:0]
+			}
+
+			results := make([]Result, 0, len(block))
+			for _, result := range block {
+				results = append(results, result.Result)
+			}
+			return results, m.Output, nil
+		case "error":
+			others = append(others, block...)
+			if len(m.Errors) > 0 {
+				return nil, nil, &RequestError{Errors: m.Errors}
+			}
+			return nil, nil, &RequestError{Errors: []Error{{Id: "session", Message: m.Error}}}
+		default:
+			others = append(others, m)
+		}
+	}
+}
+
+func (s *Session) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,192 @@
+package eflint
+
+import (
+	reasoner "github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+	"github.com/Olaf-Erkemeij/eflint-server/pkg/client"
+)
+
+// The types of package client follow the JSON specification, just like
+// those of the reasoner, so they are converted field by field.
+
+func phraseToReasoner(p Phrase) reasoner.Phrase {
+	return reasoner.Phrase{
+		Kind:          p.Kind,
+		Stateless:     p.Stateless,
+		Updates:       p.Updates,
+		Expression:    optionalExpressionToReasoner(p.Expression),
+		Operand:       optionalExpressionToReasoner(p.Operand),
+		Name:          p.Name,
+		Type:          p.Type,
+		Range:         expressionsToReasoner(p.Range),
+		WhenTrue:      p.WhenTrue,
+		DerivedFrom:   expressionsToReasoner(p.DerivedFrom),
+		HoldsWhen:     expressionsToReasoner(p.HoldsWhen),
+		ConditionedBy: expressionsToReasoner(p.ConditionedBy),
+		IdentifiedBy:  p.IdentifiedBy,
+		For:           p.For,
+		IsInvariant:   p.IsInvariant,
+		RelatedTo:     p.RelatedTo,
+		SyncsWith:     expressionsToReasoner(p.SyncsWith),
+		Creates:       expressionsToReasoner(p.Creates),
+		Terminates:    expressionsToReasoner(p.Terminates),
+		Obfuscates:    expressionsToReasoner(p.Obfuscates),
+		Actor:         p.Actor,
+		Holder:        p.Holder,
+		Claimant:      p.Claimant,
+		ViolatedWhen:  expressionsToReasoner(p.ViolatedWhen),
+		ParentKind:    p.ParentKind,
+	}
+}
+
+func phraseFromReasoner(p reasoner.Phrase) Phrase {
+	return Phrase{
+		Kind:          p.Kind,
+		Stateless:     p.Stateless,
+		Updates:       p.Updates,
+		Expression:    optionalExpressionFromReasoner(p.Expression),
+		Operand:       optionalExpressionFromReasoner(p.Operand),
+		Name:          p.Name,
+		Type:          p.Type,
+		Range:         expressionsFromReasoner(p.Range),
+		WhenTrue:      p.WhenTrue,
+		DerivedFrom:   expressionsFromReasoner(p.DerivedFrom),
+		HoldsWhen:     expressionsFromReasoner(p.HoldsWhen),
+		ConditionedBy: expressionsFromReasoner(p.ConditionedBy),
+		IdentifiedBy:  p.IdentifiedBy,
+		For:           p.For,
+		IsInvariant:   p.IsInvariant,
+		RelatedTo:     p.RelatedTo,
+		SyncsWith:     expressionsFromReasoner(p.SyncsWith),
+		Creates:       expressionsFromReasoner(p.Creates),
+		Terminates:    expressionsFromReasoner(p.Terminates),
+		Obfuscates:    expressionsFromReasoner(p.Obfuscates),
+		Actor:         p.Actor,
+		Holder:        p.Holder,
+		Claimant:      p.Claimant,
+		ViolatedWhen:  expressionsFromReasoner(p.ViolatedWhen),
+		ParentKind:    p.ParentKind,
+	}
+}
+
+func expressionToReasoner(e Expression) reasoner.Expression {
+	return reasoner.Expression{
+		Value:      normaliseValue(e.Value),
+		Operator:   e.Operator,
+		Identifier: e.Identifier,
+		Operands:   expressionsToReasoner(e.Operands),
+		Iterator:   e.Iterator,
+		Binds:      e.Binds,
+		Expression: optionalExpressionToReasoner(e.Expression),
+		Operand:    optionalExpressionToReasoner(e.Operand),
+		Parameter:  e.Parameter,
+	}
+}
+
+// normaliseValue turns integers into the int64 values that the reasoner
+// works with, for expressions that were not built with client.Int.
+func normaliseValue(value interface{}) interface{} {
+	if i, ok := value.(int); ok {
+		return int64(i)
+	}
+
+	return value
+}
+
+func optionalExpressionToReasoner(e *Expression) *reasoner.Expression {
+	if e == nil {
+		return nil
+	}
+
+	converted := expressionToReasoner(*e)
+	return &converted
+}
+
+func expressionsToReasoner(expressions []Expression) []reasoner.Expression {
+	if expressions == nil {
+		return nil
+	}
+
+	converted := make([]reasoner.Expression, 0, len(expressions))
+	for _, expression := range expressions {
+		converted = append(converted, expressionToReasoner(expression))
+	}
+
+	return converted
+}
+
+func expressionFromReasoner(e reasoner.Expression) Expression {
+	return Expression{
+		Value:      e.Value,
+		Operator:   e.Operator,
+		Identifier: e.Identifier,
+		Operands:   expressionsFromReasoner(e.Operands),
+		Iterator:   e.Iterator,
+		Binds:      e.Binds,
+		Expression: optionalExpressionFromReasoner(e.Expression),
+		Operand:    optionalExpressionFromReasoner(e.Operand),
+		Parameter:  e.Parameter,
+	}
+}
+
+func optionalExpressionFromReasoner(e *reasoner.Expression) *Expression {
+	if e == nil {
+		return nil
+	}
+
+	converted := expressionFromReasoner(*e)
+	return &converted
+}
+
+func expressionsFromReasoner(expressions []reasoner.Expression) []Expression {
+	if expressions == nil {
+		return nil
+	}
+
+	converted := make([]Expression, 0, len(expressions))
+	for _, expression := range expressions {
+		converted = append(converted, expressionFromReasoner(expression))
+	}
+
+	return converted
+}
+
+func resultFromReasoner(r reasoner.PhraseResult) Result {
+	errors := make([]client.Error, 0, len(r.Errors))
+	for _, err := range r.Errors {
+		errors = append(errors, client.Error{Id: err.Id, Code: err.Code, Message: err.Message, Pointer: err.Pointer})
+	}
+
+	switch {
+	case r.IsBquery:
+		return BQueryResult{Success: r.Success, Errors: errors, Result: r.Result}
+	case r.IsIquery:
+		return IQueryResult{Success: r.Success, Errors: errors, Result: expressionsFromReasoner(r.Results)}
+	}
+
+	changes := StateChanges{
+		Success:    r.Success,
+		Errors:     errors,
+		Changes:    make([]Phrase, 0, len(r.Changes)),
+		Triggers:   make([]client.Triggered, 0, len(r.Triggers)),
+		Violated:   r.Violated,
+		Violations: make([]Violation, 0, len(r.Violations)),
+	}
+
+	for _, change := range r.Changes {
+		changes.Changes = append(changes.Changes, phraseFromReasoner(change))
+	}
+
+	for _, trigger := range r.Triggers {
+		changes.Triggers = append(changes.Triggers, client.Triggered{Identifier: trigger.Identifier, Kind: trigger.Kind, Parent: trigger.Parent})
+	}
+
+	for _, violation := range r.Violations {
+		changes.Violations = append(changes.Violations, Violation{
+			Kind:       violation.Kind,
+			Identifier: violation.Identifier,
+			Operands:   expressionsFromReasoner(violation.Operands),
+		})
+	}
+
+	return changes
+}
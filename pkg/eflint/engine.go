@@ -0,0 +1,243 @@
+// Package eflint embeds the eFLINT reasoner, so that Go programs can evaluate
+// norms without going through the server. Every Engine has its own normative
+// state:
+//
+//	engine := eflint.NewEngine()
+//	if _, err := engine.LoadSource("norms.eflint", src); err != nil {
+//		return err
+//	}
+//
+//	engine.OnViolation(func(v eflint.Violation) { log.Println(v.Identifier) })
+//	holds, err := engine.Holds(client.Apply("citizen", client.String("Alice")))
+//
+// Phrases and expressions are built with the functions of package client,
+// which share their types with this package.
+//
+// The reasoner itself is not safe for concurrent use, so engines take turns:
+// only one phrase is evaluated at a time in the whole process.
+package eflint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	reasoner "github.com/Olaf-Erkemeij/eflint-server/internal/eflint"
+	"github.com/Olaf-Erkemeij/eflint-server/internal/parser"
+	"github.com/Olaf-Erkemeij/eflint-server/pkg/client"
+)
+
+type (
+	Expression   = client.Expression
+	Phrase       = client.Phrase
+	Result       = client.Result
+	BQueryResult = client.BQueryResult
+	IQueryResult = client.IQueryResult
+	StateChanges = client.StateChanges
+	Violation    = client.Violation
+)
+
+// Change is the creation, termination or obfuscation of an instance.
+type Change struct {
+	Kind     string
+	Instance Expression
+}
+
+// Engine is a normative state together with the specification that it
+// follows. The methods of an engine are safe for concurrent use.
+type Engine struct {
+	lock              sync.Mutex
+	state             reasoner.State
+	changeHandlers    []func(Change)
+	violationHandlers []func(Violation)
+}
+
+func NewEngine() *Engine {
+	reasoner.InterpreterLock.Lock()
+	defer reasoner.InterpreterLock.Unlock()
+
+	reasoner.Reset()
+
+	return &Engine{state: reasoner.SaveState()}
+}
+
+// OnChange registers a function that is called for every instance that is
+// created, terminated or obfuscated by the phrases of the engine. It is
+// called after the phrases have been applied, so it can use the engine.
+func (e *Engine) OnChange(handler func(Change)) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.changeHandlers = append(e.changeHandlers, handler)
+}
+
+// OnViolation registers a function that is called for every violation that
+// the phrases of the engine raise, after the phrases have been applied.
+func (e *Engine) OnViolation(handler func(Violation)) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.violationHandlers = append(e.violationHandlers, handler)
+}
+
+// LoadSource applies the phrases of an eFLINT program. Included files are
+// resolved relative to the filename.
+func (e *Engine) LoadSource(filename string, src []byte) ([]Result, error) {
+	input, err := parser.Parse(filename, bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.LoadJSON(data)
+}
+
+// LoadJSON applies the phrases of an input in the JSON specification.
+func (e *Engine) LoadJSON(data []byte) ([]Result, error) {
+	var input reasoner.Input
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, err
+	}
+
+	if input.Kind != "phrases" {
+		return nil, fmt.Errorf("expected an input of kind phrases, got %s", input.Kind)
+	}
+
+	if err := reasoner.Typecheck(input); err != nil {
+		return nil, err
+	}
+
+	return e.apply(input.Phrases)
+}
+
+// Apply applies the phrases in order. If any of them fails, the state of the
+// engine is left as it was, and the error is returned.
+func (e *Engine) Apply(phrases ...Phrase) ([]Result, error) {
+	converted := make([]reasoner.Phrase, 0, len(phrases))
+	for _, phrase := range phrases {
+		converted = append(converted, phraseToReasoner(phrase))
+	}
+
+	if err := reasoner.TypecheckPhrases(converted); err != nil {
+		return nil, err
+	}
+
+	return e.apply(converted)
+}
+
+// Holds returns whether the expression holds.
+func (e *Engine) Holds(expression Expression) (bool, error) {
+	results, err := e.Apply(client.BQuery(expression))
+	if err != nil {
+		return false, err
+	}
+
+	result, ok := results[0].(BQueryResult)
+	if !ok {
+		return false, fmt.Errorf("expected the result of a boolean query, got %T", results[0])
+	}
+
+	return result.Result, nil
+}
+
+// Query returns the instances of the expression.
+func (e *Engine) Query(expression Expression) ([]Expression, error) {
+	results, err := e.Apply(client.IQuery(expression))
+	if err != nil {
+		return nil, err
+	}
+
+	result, ok := results[0].(IQueryResult)
+	if !ok {
+		return nil, fmt.Errorf("expected the result of an instance query, got %T", results[0])
+	}
+
+	return result.Result, nil
+}
+
+// Instances returns the instances that currently hold, ordered by the name of
+// their fact.
+func (e *Engine) Instances() []Expression {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	reasoner.InterpreterLock.Lock()
+	defer reasoner.InterpreterLock.Unlock()
+
+	reasoner.Reset()
+	reasoner.RestoreState(e.state)
+
+	return expressionsFromReasoner(reasoner.AllInstances())
+}
+
+func (e *Engine) apply(phrases []reasoner.Phrase) ([]Result, error) {
+	e.lock.Lock()
+	results, err := e.interpret(phrases)
+	changeHandlers, violationHandlers := e.changeHandlers, e.violationHandlers
+	e.lock.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		changes, ok := result.(StateChanges)
+		if !ok {
+			continue
+		}
+
+		for _, change := range changes.Changes {
+			if change.Operand == nil {
+				continue
+			}
+
+			for _, handler := range changeHandlers {
+				handler(Change{Kind: change.Kind, Instance: *change.Operand})
+			}
+		}
+
+		for _, violation := range changes.Violations {
+			for _, handler := range violationHandlers {
+				handler(violation)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// interpret runs the phrases in the state of the engine, and keeps the new
+// state if all of them succeed.
+func (e *Engine) interpret(phrases []reasoner.Phrase) (results []Result, err error) {
+	reasoner.InterpreterLock.Lock()
+	defer reasoner.InterpreterLock.Unlock()
+
+	reasoner.Reset()
+	reasoner.RestoreState(e.state)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("interpreter failed: %v", r)
+		}
+	}()
+
+	results = make([]Result, 0, len(phrases))
+
+	for i, phrase := range phrases {
+		if err := reasoner.InterpretPhrase(phrase); err != nil {
+			return nil, fmt.Errorf("phrase %d: %w", i, err)
+		}
+
+		all := reasoner.Results()
+		results = append(results, resultFromReasoner(all[len(all)-1]))
+	}
+
+	e.state = reasoner.SaveState()
+
+	return results, nil
+}
@@ -0,0 +1,65 @@
+package eflint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Olaf-Erkemeij/eflint-server/pkg/client"
+)
+
+func TestEngine(t *testing.T) {
+	engine := NewEngine()
+
+	created := make([]string, 0)
+	violated := make([]string, 0)
+	engine.OnChange(func(change Change) {
+		if change.Kind == "create" {
+			created = append(created, change.Instance.Name())
+		}
+	})
+	engine.OnViolation(func(violation Violation) { violated = append(violated, violation.Identifier) })
+
+	_, err := engine.LoadSource("engine.eflint", []byte(`
+		Fact citizen Identified by String.
+		Duty pay Holder citizen Claimant citizen Violated when True.
+		+citizen(Alice).
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alice := client.Apply("citizen", client.String("Alice"))
+	bob := client.Apply("citizen", client.String("Bob"))
+
+	if _, err := engine.Apply(client.Create(client.Apply("pay", client.String("Alice"), client.String("Bob")))); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(created, ",") != "citizen,pay" || strings.Join(violated, ",") != "pay" {
+		t.Errorf("Expected the creation of citizen and pay and the violation of pay, got %v and %v", created, violated)
+	}
+
+	// A second engine has its own state
+	other := NewEngine()
+	if _, err := other.LoadJSON([]byte(`{"version": "0.1.0", "kind": "phrases", "phrases": [{"kind": "afact", "name": "citizen", "type": "String"}, {"kind": "create", "operand": {"identifier": "citizen", "operands": ["Bob"]}}]}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if holds, err := engine.Holds(alice); err != nil || !holds {
+		t.Errorf("Expected citizen(Alice) to hold, got %v (%v)", holds, err)
+	}
+	if holds, err := engine.Holds(bob); err != nil || holds {
+		t.Errorf("Expected citizen(Bob) not to hold in the first engine, got %v (%v)", holds, err)
+	}
+	if instances, err := other.Query(client.Var("citizen")); err != nil || len(instances) != 1 {
+		t.Errorf("Expected a single citizen in the second engine, got %+v (%v)", instances, err)
+	}
+
+	// Failing phrases leave the state as it was
+	if _, err := engine.Apply(client.Terminate(alice), client.Trigger(client.Apply("undeclared"))); err == nil {
+		t.Error("Expected triggering an undeclared act to fail")
+	}
+	if len(engine.Instances()) != 2 {
+		t.Errorf("Expected citizen(Alice) and pay(Alice, Bob) to remain, got %+v", engine.Instances())
+	}
+}